@@ -1,92 +1,257 @@
 package ast
 
-type Node struct {
-	Type  string
-	Start int
-	End   int
+import "github.com/iamBharatManral/atom.git/cmd/internal/token"
+
+// Node is a node of the syntax tree.
+type Node interface {
+	// Pos returns the position of the first rune of the node.
+	Pos() token.Position
+	// End returns the offset of the last rune of the node.
+	End() int
+	// String formats the node as source code that parses back into it.
+	String() string
+}
+
+// Statement is a node that can stand on a line of its own. Every
+// expression is also a statement.
+type Statement interface {
+	Node
+	statementNode()
+}
+
+// Expression is a node that produces a value.
+type Expression interface {
+	Statement
+	expressionNode()
+}
+
+// Span holds what every node has in common: Start and Stop are the offsets
+// of its first and last runes and Position is the position of its first
+// one.
+type Span struct {
+	Type     string
+	Start    int
+	Stop     int
+	Position token.Position
 }
 
-type (
-	Statement interface{}
-)
+func (s Span) Pos() token.Position { return s.Position }
+func (s Span) End() int            { return s.Stop }
 
 type Literal struct {
 	Value any
-	Node
+	Span
 	UnaryOp string
 }
 
-type Expression interface{}
-
 type UnaryExpression struct {
 	Value    Expression
 	Operator string
-	Node
+	Span
 }
 
 type BinaryExpression struct {
 	Left     Expression
 	Right    Expression
 	Operator string
-	Node
+	Span
 }
 
 type Identifier struct {
 	Value string
-	Node
+	Span
 	UnaryOp string
 }
 
-type ErrorStatement struct {
-	Reason string
-}
-
 type LetStatement struct {
 	Left     Identifier
-	Right    any
+	Right    Expression
 	Operator string
-	Node
+	Span
+	Constant bool
 }
 
 type AssignmentStatement struct {
 	Left     Identifier
-	Right    any
+	Right    Expression
 	Operator string
-	Node
+	Span
+}
+
+type BlockStatement struct {
+	Body []Statement
+	Span
 }
 
 type IfElseBlock struct {
 	Consequent Statement
 	Alternate  Statement
-	Test       any
-	Node
+	Test       Expression
+	Span
 }
 
 type IfBlock struct {
-	Node
+	Span
 	Consequent Statement
-	Test       any
+	Test       Expression
 }
 
 type FunctionExpression struct {
 	Body []Statement
-	Node
+	Span
 	Name       Identifier
 	Parameters []Identifier
 }
 
+// FunctionEvaluation calls the function bound to Name or, when Callee is
+// set, the function that Callee evaluates to.
 type FunctionEvaluation struct {
-	Node
-	Arguments []Statement
+	Span
+	Arguments []Expression
 	Name      Identifier
+	Callee    Expression
+}
+
+type WhileExpression struct {
+	Test Expression
+	Body []Statement
+	Span
+}
+
+type ForExpression struct {
+	Variable Identifier
+	Iterable Expression
+	Body     []Statement
+	Span
+}
+
+type RangeExpression struct {
+	From      Expression
+	To        Expression
+	Step      Expression
+	Inclusive bool
+	Span
+}
+
+type ListLiteral struct {
+	Elements []Expression
+	Span
+}
+
+// InterpolatedString is a string literal with embedded expressions. Its
+// parts are string Literals for the plain text and the expressions in order.
+type InterpolatedString struct {
+	Parts []Expression
+	Span
+}
+
+type MatchExpression struct {
+	Subject Expression
+	Clauses []MatchClause
+	Span
+}
+
+// MatchClause is a single 'pattern if guard -> body' arm of a match
+// expression. Guard is nil when the clause has none.
+type MatchClause struct {
+	Pattern Expression
+	Guard   Expression
+	Body    Statement
+	Span
+}
+
+type TupleLiteral struct {
+	Elements []Expression
+	Span
+}
+
+type MapLiteral struct {
+	Keys   []Expression
+	Values []Expression
+	Span
+}
+
+type IndexAssignmentStatement struct {
+	Left     IndexExpression
+	Right    Expression
+	Operator string
+	Span
+}
+
+type IndexExpression struct {
+	Left  Expression
+	Index Expression
+	Span
+}
+
+type SliceExpression struct {
+	Left Expression
+	Low  Expression
+	High Expression
+	Span
+}
+
+type BreakStatement struct {
+	Value Expression
+	Span
+}
+
+type ContinueStatement struct {
+	Span
 }
 
 type ReturnStatement struct {
-	Value Statement
-	Node
+	Value Expression
+	Span
 }
 
 type Program struct {
 	Body []Statement
-	Node
+	Span
 }
+
+func (BlockStatement) statementNode()           {}
+func (LetStatement) statementNode()             {}
+func (AssignmentStatement) statementNode()      {}
+func (IndexAssignmentStatement) statementNode() {}
+func (BreakStatement) statementNode()           {}
+func (ContinueStatement) statementNode()        {}
+func (ReturnStatement) statementNode()          {}
+
+func (Literal) statementNode()            {}
+func (Identifier) statementNode()         {}
+func (UnaryExpression) statementNode()    {}
+func (BinaryExpression) statementNode()   {}
+func (IfBlock) statementNode()            {}
+func (IfElseBlock) statementNode()        {}
+func (FunctionExpression) statementNode() {}
+func (FunctionEvaluation) statementNode() {}
+func (WhileExpression) statementNode()    {}
+func (ForExpression) statementNode()      {}
+func (RangeExpression) statementNode()    {}
+func (ListLiteral) statementNode()        {}
+func (TupleLiteral) statementNode()       {}
+func (MapLiteral) statementNode()         {}
+func (InterpolatedString) statementNode() {}
+func (MatchExpression) statementNode()    {}
+func (IndexExpression) statementNode()    {}
+func (SliceExpression) statementNode()    {}
+
+func (Literal) expressionNode()            {}
+func (Identifier) expressionNode()         {}
+func (UnaryExpression) expressionNode()    {}
+func (BinaryExpression) expressionNode()   {}
+func (IfBlock) expressionNode()            {}
+func (IfElseBlock) expressionNode()        {}
+func (FunctionExpression) expressionNode() {}
+func (FunctionEvaluation) expressionNode() {}
+func (WhileExpression) expressionNode()    {}
+func (ForExpression) expressionNode()      {}
+func (RangeExpression) expressionNode()    {}
+func (ListLiteral) expressionNode()        {}
+func (TupleLiteral) expressionNode()       {}
+func (MapLiteral) expressionNode()         {}
+func (InterpolatedString) expressionNode() {}
+func (MatchExpression) expressionNode()    {}
+func (IndexExpression) expressionNode()    {}
+func (SliceExpression) expressionNode()    {}
@@ -0,0 +1,299 @@
+package ast
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+)
+
+func (n Program) String() string {
+	texts := make([]string, len(n.Body))
+	for i, stmt := range n.Body {
+		texts[i] = stmt.String()
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (n Literal) String() string {
+	switch value := n.Value.(type) {
+	case string:
+		return n.UnaryOp + quote(value)
+	case int:
+		return n.UnaryOp + strconv.Itoa(value)
+	case *big.Int:
+		return n.UnaryOp + value.String()
+	case float64:
+		text := strconv.FormatFloat(value, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eIN") {
+			text += ".0"
+		}
+		return n.UnaryOp + text
+	case decimal.Decimal:
+		return n.UnaryOp + value.String() + "d"
+	}
+	return n.UnaryOp + "<?>"
+}
+
+func (n Identifier) String() string {
+	return n.UnaryOp + n.Value
+}
+
+func (n UnaryExpression) String() string {
+	return n.Operator + operand(n.Value)
+}
+
+func (n BinaryExpression) String() string {
+	return operand(n.Left) + " " + n.Operator + " " + operand(n.Right)
+}
+
+func (n RangeExpression) String() string {
+	operator := ".."
+	if !n.Inclusive {
+		operator = "..<"
+	}
+	text := operand(n.From) + " " + operator + " " + operand(n.To)
+	if n.Step != nil {
+		text += " by " + operand(n.Step)
+	}
+	return text
+}
+
+func (n LetStatement) String() string {
+	keyword := "let"
+	if n.Constant {
+		keyword = "const"
+	}
+	return keyword + " " + n.Left.String() + " = " + n.Right.String()
+}
+
+func (n AssignmentStatement) String() string {
+	return n.Left.String() + " = " + n.Right.String()
+}
+
+func (n IndexAssignmentStatement) String() string {
+	return n.Left.String() + " = " + n.Right.String()
+}
+
+func (n BlockStatement) String() string {
+	texts := make([]string, len(n.Body))
+	for i, stmt := range n.Body {
+		texts[i] = stmt.String()
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (n IfBlock) String() string {
+	if block, ok := n.Consequent.(BlockStatement); ok {
+		return blockIf("if", n.Test, block, nil)
+	}
+	return "if " + n.Test.String() + " do " + n.Consequent.String()
+}
+
+func (n IfElseBlock) String() string {
+	if block, ok := n.Consequent.(BlockStatement); ok {
+		return blockIf("if", n.Test, block, n.Alternate)
+	}
+	return "if " + n.Test.String() + " do " + n.Consequent.String() + " else " + n.Alternate.String()
+}
+
+// blockIf formats the block form of an if expression, whose 'elif' and
+// 'else' branches share the 'end' closing the whole chain.
+func blockIf(keyword string, test Expression, consequent BlockStatement, alternate Statement) string {
+	text := keyword + " " + test.String() + " do" + lines(consequent.Body)
+	switch alternate := alternate.(type) {
+	case IfBlock:
+		if block, ok := alternate.Consequent.(BlockStatement); ok {
+			return text + blockIf("elif", alternate.Test, block, nil)
+		}
+	case IfElseBlock:
+		if block, ok := alternate.Consequent.(BlockStatement); ok {
+			return text + blockIf("elif", alternate.Test, block, alternate.Alternate)
+		}
+	case BlockStatement:
+		return text + "else" + lines(alternate.Body) + "end"
+	}
+	return text + "end"
+}
+
+func (n FunctionExpression) String() string {
+	parameters := make([]string, len(n.Parameters))
+	for i, parameter := range n.Parameters {
+		parameters[i] = parameter.String()
+	}
+	text := "fn "
+	if n.Name.Value != "" {
+		text += n.Name.Value + " "
+	}
+	return text + "|" + strings.Join(parameters, ", ") + "| ->" + lines(n.Body) + "end"
+}
+
+func (n FunctionEvaluation) String() string {
+	callee := n.Name.String()
+	if n.Callee != nil {
+		callee = operand(n.Callee)
+	}
+	return callee + "(" + list(n.Arguments) + ")"
+}
+
+func (n WhileExpression) String() string {
+	return "while " + n.Test.String() + " do" + lines(n.Body) + "end"
+}
+
+func (n ForExpression) String() string {
+	return "for " + n.Variable.String() + " in " + n.Iterable.String() + " do" + lines(n.Body) + "end"
+}
+
+func (n ListLiteral) String() string {
+	return "[" + list(n.Elements) + "]"
+}
+
+func (n TupleLiteral) String() string {
+	if len(n.Elements) == 1 {
+		return "(" + n.Elements[0].String() + ",)"
+	}
+	return "(" + list(n.Elements) + ")"
+}
+
+func (n MapLiteral) String() string {
+	entries := make([]string, len(n.Keys))
+	for i := range n.Keys {
+		entries[i] = n.Keys[i].String() + ": " + n.Values[i].String()
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func (n InterpolatedString) String() string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, part := range n.Parts {
+		if text, ok := part.(Literal); ok {
+			if value, ok := text.Value.(string); ok {
+				b.WriteString(escape(value))
+				continue
+			}
+		}
+		b.WriteString("#{" + part.String() + "}")
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func (n MatchExpression) String() string {
+	return "match " + n.Subject.String() + " do" + lines(n.Clauses) + "end"
+}
+
+func (n MatchClause) String() string {
+	text := n.Pattern.String()
+	if n.Guard != nil {
+		text += " if " + n.Guard.String()
+	}
+	return text + " -> " + n.Body.String()
+}
+
+func (n IndexExpression) String() string {
+	return operand(n.Left) + "[" + n.Index.String() + "]"
+}
+
+func (n SliceExpression) String() string {
+	text := operand(n.Left) + "["
+	if n.Low != nil {
+		text += n.Low.String()
+	}
+	text += ":"
+	if n.High != nil {
+		text += n.High.String()
+	}
+	return text + "]"
+}
+
+func (n BreakStatement) String() string {
+	if n.Value == nil {
+		return "break"
+	}
+	return "break " + n.Value.String()
+}
+
+func (n ContinueStatement) String() string {
+	return "continue"
+}
+
+func (n ReturnStatement) String() string {
+	return "return " + n.Value.String()
+}
+
+// operand formats an expression used as the operand of an operator, a
+// callee or an indexed value, in parentheses unless it is a single term.
+func operand(e Expression) string {
+	switch e := e.(type) {
+	case Literal:
+		if e.UnaryOp == "" {
+			return e.String()
+		}
+	case Identifier:
+		if e.UnaryOp == "" {
+			return e.String()
+		}
+	case ListLiteral, TupleLiteral, MapLiteral, InterpolatedString, FunctionEvaluation, IndexExpression, SliceExpression:
+		return e.String()
+	}
+	return "(" + e.String() + ")"
+}
+
+// lines formats the statements of a block one per line, indented under the
+// line opening the block.
+func lines[N Node](body []N) string {
+	var b strings.Builder
+	for _, stmt := range body {
+		b.WriteString("\n  ")
+		b.WriteString(strings.ReplaceAll(stmt.String(), "\n", "\n  "))
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+func list(elements []Expression) string {
+	texts := make([]string, len(elements))
+	for i, element := range elements {
+		texts[i] = element.String()
+	}
+	return strings.Join(texts, ", ")
+}
+
+// quote formats s as a string literal.
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+// escape escapes s for the inside of a string literal, including a '#'
+// that would otherwise start an interpolation.
+func escape(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case 0:
+			b.WriteString(`\0`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '#':
+			if i+1 < len(runes) && runes[i+1] == '{' {
+				b.WriteString(`\#`)
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,293 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children of
+// node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order, visiting
+// the children of a node in the order they appear in the source. Absent
+// optional children, such as the step of a range or the name of an
+// anonymous function, are not visited.
+func Walk(v Visitor, node Node) {
+	if node == nil {
+		return
+	}
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	switch n := node.(type) {
+	case Program:
+		walkList(v, n.Body)
+	case BlockStatement:
+		walkList(v, n.Body)
+	case Literal, Identifier, ContinueStatement:
+	case UnaryExpression:
+		Walk(v, n.Value)
+	case BinaryExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case RangeExpression:
+		Walk(v, n.From)
+		Walk(v, n.To)
+		Walk(v, n.Step)
+	case LetStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case AssignmentStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case IndexAssignmentStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case IfBlock:
+		Walk(v, n.Test)
+		Walk(v, n.Consequent)
+	case IfElseBlock:
+		Walk(v, n.Test)
+		Walk(v, n.Consequent)
+		Walk(v, n.Alternate)
+	case FunctionExpression:
+		if n.Name.Value != "" {
+			Walk(v, n.Name)
+		}
+		walkList(v, n.Parameters)
+		walkList(v, n.Body)
+	case FunctionEvaluation:
+		if n.Callee != nil {
+			Walk(v, n.Callee)
+		} else {
+			Walk(v, n.Name)
+		}
+		walkList(v, n.Arguments)
+	case WhileExpression:
+		Walk(v, n.Test)
+		walkList(v, n.Body)
+	case ForExpression:
+		Walk(v, n.Variable)
+		Walk(v, n.Iterable)
+		walkList(v, n.Body)
+	case ListLiteral:
+		walkList(v, n.Elements)
+	case TupleLiteral:
+		walkList(v, n.Elements)
+	case MapLiteral:
+		for i := range n.Keys {
+			Walk(v, n.Keys[i])
+			Walk(v, n.Values[i])
+		}
+	case InterpolatedString:
+		walkList(v, n.Parts)
+	case MatchExpression:
+		Walk(v, n.Subject)
+		walkList(v, n.Clauses)
+	case MatchClause:
+		Walk(v, n.Pattern)
+		Walk(v, n.Guard)
+		Walk(v, n.Body)
+	case IndexExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+	case SliceExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Low)
+		Walk(v, n.High)
+	case BreakStatement:
+		Walk(v, n.Value)
+	case ReturnStatement:
+		Walk(v, n.Value)
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+	v.Visit(nil)
+}
+
+func walkList[N Node](v Visitor, list []N) {
+	for _, node := range list {
+		Walk(v, node)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node in the order of Walk, calling
+// f(node) for each node and then f(nil) once its children are done. If f
+// returns false the children of the node are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Rewrite returns a copy of the tree rooted at node in which every node has
+// been replaced by f applied to it. Children are rewritten before their
+// parent, so f sees a node whose children have already been replaced.
+// Returning the node unchanged keeps it; returning nil removes it from the
+// list holding it, such as a block body or the arguments of a call, and
+// clears it anywhere else.
+//
+// A replacement must be usable where the original appears: an expression
+// for an expression, and a node of the same type where a field has a
+// concrete type, such as the name of a function. Rewrite panics otherwise.
+func Rewrite(node Node, f func(Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+	switch n := node.(type) {
+	case Program:
+		n.Body = rewriteList(n.Body, f)
+		node = n
+	case BlockStatement:
+		n.Body = rewriteList(n.Body, f)
+		node = n
+	case Literal, Identifier, ContinueStatement:
+	case UnaryExpression:
+		n.Value = rewrite(n.Value, f)
+		node = n
+	case BinaryExpression:
+		n.Left = rewrite(n.Left, f)
+		n.Right = rewrite(n.Right, f)
+		node = n
+	case RangeExpression:
+		n.From = rewrite(n.From, f)
+		n.To = rewrite(n.To, f)
+		n.Step = rewrite(n.Step, f)
+		node = n
+	case LetStatement:
+		n.Left = rewrite(n.Left, f)
+		n.Right = rewrite(n.Right, f)
+		node = n
+	case AssignmentStatement:
+		n.Left = rewrite(n.Left, f)
+		n.Right = rewrite(n.Right, f)
+		node = n
+	case IndexAssignmentStatement:
+		n.Left = rewrite(n.Left, f)
+		n.Right = rewrite(n.Right, f)
+		node = n
+	case IfBlock:
+		n.Test = rewrite(n.Test, f)
+		n.Consequent = rewrite(n.Consequent, f)
+		node = n
+	case IfElseBlock:
+		n.Test = rewrite(n.Test, f)
+		n.Consequent = rewrite(n.Consequent, f)
+		n.Alternate = rewrite(n.Alternate, f)
+		node = n
+	case FunctionExpression:
+		if n.Name.Value != "" {
+			n.Name = rewrite(n.Name, f)
+		}
+		n.Parameters = rewriteList(n.Parameters, f)
+		n.Body = rewriteList(n.Body, f)
+		node = n
+	case FunctionEvaluation:
+		if n.Callee != nil {
+			n.Callee = rewrite(n.Callee, f)
+		} else {
+			n.Name = rewrite(n.Name, f)
+		}
+		n.Arguments = rewriteList(n.Arguments, f)
+		node = n
+	case WhileExpression:
+		n.Test = rewrite(n.Test, f)
+		n.Body = rewriteList(n.Body, f)
+		node = n
+	case ForExpression:
+		n.Variable = rewrite(n.Variable, f)
+		n.Iterable = rewrite(n.Iterable, f)
+		n.Body = rewriteList(n.Body, f)
+		node = n
+	case ListLiteral:
+		n.Elements = rewriteList(n.Elements, f)
+		node = n
+	case TupleLiteral:
+		n.Elements = rewriteList(n.Elements, f)
+		node = n
+	case MapLiteral:
+		keys := make([]Expression, 0, len(n.Keys))
+		values := make([]Expression, 0, len(n.Values))
+		for i := range n.Keys {
+			key, value := rewrite(n.Keys[i], f), rewrite(n.Values[i], f)
+			if key != nil && value != nil {
+				keys, values = append(keys, key), append(values, value)
+			}
+		}
+		n.Keys, n.Values = keys, values
+		node = n
+	case InterpolatedString:
+		n.Parts = rewriteList(n.Parts, f)
+		node = n
+	case MatchExpression:
+		n.Subject = rewrite(n.Subject, f)
+		n.Clauses = rewriteList(n.Clauses, f)
+		node = n
+	case MatchClause:
+		n.Pattern = rewrite(n.Pattern, f)
+		n.Guard = rewrite(n.Guard, f)
+		n.Body = rewrite(n.Body, f)
+		node = n
+	case IndexExpression:
+		n.Left = rewrite(n.Left, f)
+		n.Index = rewrite(n.Index, f)
+		node = n
+	case SliceExpression:
+		n.Left = rewrite(n.Left, f)
+		n.Low = rewrite(n.Low, f)
+		n.High = rewrite(n.High, f)
+		node = n
+	case BreakStatement:
+		n.Value = rewrite(n.Value, f)
+		node = n
+	case ReturnStatement:
+		n.Value = rewrite(n.Value, f)
+		node = n
+	default:
+		panic(fmt.Sprintf("ast.Rewrite: unexpected node type %T", n))
+	}
+	return f(node)
+}
+
+// rewrite rewrites a child of type N, which is the zero N when the child
+// is absent or has been removed.
+func rewrite[N Node](node N, f func(Node) Node) N {
+	var zero N
+	if Node(node) == nil {
+		return zero
+	}
+	replaced := Rewrite(node, f)
+	if replaced == nil {
+		return zero
+	}
+	child, ok := replaced.(N)
+	if !ok {
+		panic(fmt.Sprintf("ast.Rewrite: cannot replace %T with %T", node, replaced))
+	}
+	return child
+}
+
+func rewriteList[N Node](list []N, f func(Node) Node) []N {
+	if list == nil {
+		return nil
+	}
+	rewritten := make([]N, 0, len(list))
+	for _, node := range list {
+		if replaced := Rewrite(node, f); replaced != nil {
+			child, ok := replaced.(N)
+			if !ok {
+				panic(fmt.Sprintf("ast.Rewrite: cannot replace %T with %T", node, replaced))
+			}
+			rewritten = append(rewritten, child)
+		}
+	}
+	return rewritten
+}
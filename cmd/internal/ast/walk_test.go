@@ -0,0 +1,153 @@
+package ast_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
+	"github.com/iamBharatManral/atom.git/cmd/internal/lexer"
+	"github.com/iamBharatManral/atom.git/cmd/internal/parser"
+)
+
+func parse(t *testing.T, input string) ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New([]rune(input)))
+	program := p.Parse()
+	if len(p.Errors) > 0 {
+		t.Fatal(p.Errors)
+	}
+	return program
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		skip  string
+		want  []string
+	}{
+		{name: "source order", input: "let a = b + c * d\nf(a, -e)[g:]", want: []string{"a", "b", "c", "d", "f", "a", "e", "g"}},
+		{name: "every kind of child", input: "fn f |x| -> return x end\nfor i in 0..n by s do\n  m[i] = {k: v}\nend\nmatch p do\n  (q, _) if q -> \"#{r}\"\nend\nif t do\n  u\nelif w do\n  break y\nend",
+			want: []string{"f", "x", "x", "i", "n", "s", "m", "i", "k", "v", "p", "q", "_", "q", "r", "t", "u", "w", "y"}},
+		{name: "callee expression", input: "(fn |x| -> x end)(z)", want: []string{"x", "x", "z"}},
+		{name: "skipped children", input: "let a = fn f |x| -> y end\nwhile b do c end\nz", skip: "FunctionExpression", want: []string{"a", "b", "c", "z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			ast.Inspect(parse(t, tt.input), func(node ast.Node) bool {
+				switch node := node.(type) {
+				case ast.Identifier:
+					got = append(got, node.Value)
+				case ast.FunctionExpression:
+					return node.Type != tt.skip
+				}
+				return true
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// depth tracks how deeply Walk has descended, using the Visit(nil) call
+// that ends every node visited with a non-nil visitor.
+type depth struct {
+	current, max int
+}
+
+func (d *depth) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		d.current--
+		return nil
+	}
+	d.current++
+	if d.current > d.max {
+		d.max = d.current
+	}
+	return d
+}
+
+func TestWalk(t *testing.T) {
+	d := &depth{}
+	ast.Walk(d, parse(t, "while a do\n  b = [1, (2 + 3)]\nend"))
+	if d.current != 0 || d.max != 6 {
+		t.Errorf("got depth %d and max depth %d, want 0 and 6", d.current, d.max)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	fold := func(node ast.Node) ast.Node {
+		sum, ok := node.(ast.BinaryExpression)
+		if !ok || sum.Operator != "+" {
+			return node
+		}
+		left, lok := sum.Left.(ast.Literal)
+		right, rok := sum.Right.(ast.Literal)
+		if !lok || !rok {
+			return node
+		}
+		l, lok := left.Value.(int)
+		r, rok := right.Value.(int)
+		if !lok || !rok {
+			return node
+		}
+		return ast.Literal{Value: l + r, Span: sum.Span}
+	}
+	dropLiterals := func(node ast.Node) ast.Node {
+		if _, ok := node.(ast.Literal); ok {
+			return nil
+		}
+		return node
+	}
+	rename := func(node ast.Node) ast.Node {
+		if id, ok := node.(ast.Identifier); ok {
+			id.Value = strings.ToUpper(id.Value)
+			return id
+		}
+		return node
+	}
+	tests := []struct {
+		name  string
+		input string
+		f     func(ast.Node) ast.Node
+		want  string
+	}{
+		{name: "bottom up", input: "let a = 1 + 2 + x + (3 + 4)", f: fold, want: "let a = (3 + x) + 7"},
+		{name: "nested bodies", input: "fn f |x| -> x + (1 + 1) end\nmatch y do\n  _ if 2 + 2 -> [0 + 1]\nend", f: fold,
+			want: "fn f |x| ->\n  x + 2\nend\nmatch y do\n  _ if 4 -> [1]\nend"},
+		{name: "removed from lists", input: "f(1, x, 2)\n3\n[y, 4]\n{5: z, w: 6, v: u}\nfor i in xs[1:] do\n  i\n  7\nend", f: dropLiterals,
+			want: "f(x)\n[y]\n{v: u}\nfor i in xs[:] do\n  i\nend"},
+		{name: "concrete fields", input: "fn f |a| -> g(a) end\nlet b = c[0]\nb[1] = 2", f: rename,
+			want: "fn F |A| ->\n  G(A)\nend\nlet B = C[0]\nB[1] = 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+			before := program.String()
+			got := ast.Rewrite(program, tt.f)
+			if got.String() != tt.want {
+				t.Errorf("got %q, want %q", got.String(), tt.want)
+			}
+			if program.String() != before {
+				t.Errorf("the original tree was modified to %q", program.String())
+			}
+		})
+	}
+}
+
+func TestRewritePanicsOnMismatchedReplacement(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic replacing the name of a let with a literal")
+		}
+	}()
+	ast.Rewrite(parse(t, "let a = 1"), func(node ast.Node) ast.Node {
+		if _, ok := node.(ast.Identifier); ok {
+			return ast.Literal{Value: 1}
+		}
+		return node
+	})
+}
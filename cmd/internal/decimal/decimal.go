@@ -0,0 +1,269 @@
+// Package decimal implements exact base-10 numbers for arithmetic, such as
+// money, where the rounding errors of binary floating point are not
+// acceptable.
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrRange is returned for results that would have more digits than
+	// MaxScale and maxPowBits allow.
+	ErrRange = errors.New("decimal out of range")
+)
+
+// MaxScale bounds how many digits a decimal may have after its decimal
+// point, and how many zeros an exponent may append to it, so that a literal
+// such as 1e2000000000 is rejected instead of computed.
+const MaxScale = 100_000
+
+// maxPowBits bounds the size of the coefficient computed by Pow.
+const maxPowBits = 1 << 22
+
+// RoundingMode decides how a number is rounded when digits have to be
+// dropped.
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest neighbour, and ties to the even one.
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest neighbour, and ties away from zero.
+	HalfUp
+	// HalfDown rounds to the nearest neighbour, and ties toward zero.
+	HalfDown
+	// Up rounds away from zero.
+	Up
+	// Down rounds toward zero.
+	Down
+	// Ceiling rounds toward positive infinity.
+	Ceiling
+	// Floor rounds toward negative infinity.
+	Floor
+)
+
+var roundingModes = []string{"half_even", "half_up", "half_down", "up", "down", "ceiling", "floor"}
+
+func (m RoundingMode) String() string {
+	return roundingModes[m]
+}
+
+// ParseRoundingMode returns the rounding mode with the given name, such as
+// "half_even" or "floor".
+func ParseRoundingMode(name string) (RoundingMode, bool) {
+	for i, mode := range roundingModes {
+		if mode == name {
+			return RoundingMode(i), true
+		}
+	}
+	return 0, false
+}
+
+// Decimal is the number coef × 10^-scale. The scale is kept, so 12.30 and
+// 12.3 are equal but print differently. The zero value is 0.
+type Decimal struct {
+	coef  *big.Int
+	scale int
+}
+
+var ten = big.NewInt(10)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
+
+// New returns coef × 10^-scale.
+func New(coef *big.Int, scale int) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(coef, pow10(-scale))}
+	}
+	return Decimal{coef: new(big.Int).Set(coef), scale: scale}
+}
+
+func FromInt(n *big.Int) Decimal {
+	return New(n, 0)
+}
+
+// Parse reads a decimal such as "-12.30" or "6.02e23".
+func Parse(s string) (Decimal, error) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		if _, err := fmt.Sscan(s[i+1:], &exponent); err != nil || strings.ContainsAny(s[i+1:], " \t\n") {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	digits := whole + fraction
+	if strings.TrimLeft(digits, "+-") == "" || strings.ContainsAny(strings.TrimLeft(digits, "+-"), "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	if exponent > MaxScale || exponent < -MaxScale || len(fraction)-exponent > MaxScale {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrRange, s)
+	}
+	return New(coef, len(fraction)-exponent), nil
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// rescale returns the coefficient of d at a scale at least as large as its
+// own.
+func (d Decimal) rescale(scale int) *big.Int {
+	return new(big.Int).Mul(d.coefficient(), pow10(scale-d.scale))
+}
+
+// align returns the coefficients of d and e at their common scale.
+func align(d, e Decimal) (*big.Int, *big.Int, int) {
+	scale := d.scale
+	if e.scale > scale {
+		scale = e.scale
+	}
+	return d.rescale(scale), e.rescale(scale), scale
+}
+
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), scale: d.scale}
+}
+
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return Decimal{coef: a.Add(a, b), scale: scale}
+}
+
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return Decimal{coef: a.Sub(a, b), scale: scale}
+}
+
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), e.coefficient()), scale: d.scale + e.scale}
+}
+
+// Quo returns d / e rounded to scale digits after the decimal point.
+func (d Decimal) Quo(e Decimal, scale int, mode RoundingMode) (Decimal, error) {
+	if e.Sign() == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	// d / e × 10^scale = d.coef × 10^(e.scale - d.scale + scale) / e.coef
+	num, den := new(big.Int).Set(d.coefficient()), new(big.Int).Set(e.coefficient())
+	if shift := e.scale - d.scale + scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return Decimal{coef: divide(num, den, mode), scale: scale}, nil
+}
+
+// Mod returns the remainder of flooring d / e, which has the sign of e.
+func (d Decimal) Mod(e Decimal) (Decimal, error) {
+	q, err := d.Quo(e, 0, Floor)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return d.Sub(e.Mul(q)), nil
+}
+
+// Pow returns d raised to a non-negative integer power. It returns ErrRange
+// when the result would have more than MaxScale digits after the decimal
+// point or a coefficient of more than maxPowBits bits.
+func (d Decimal) Pow(n int) (Decimal, error) {
+	coef := d.coefficient()
+	if n > 1 && (d.scale > MaxScale/n || coef.CmpAbs(big.NewInt(1)) > 0 && coef.BitLen()-1 > maxPowBits/n) {
+		return Decimal{}, ErrRange
+	}
+	return Decimal{coef: new(big.Int).Exp(coef, big.NewInt(int64(n)), nil), scale: d.scale * n}, nil
+}
+
+// Round returns d with exactly scale digits after the decimal point,
+// rounding with mode when digits have to be dropped. A negative scale
+// rounds to tens, hundreds and so on.
+func (d Decimal) Round(scale int, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return Decimal{coef: d.rescale(scale), scale: scale}
+	}
+	return New(divide(d.coefficient(), pow10(d.scale-scale), mode), scale)
+}
+
+// Int returns the integer part of d, truncating toward zero.
+func (d Decimal) Int() *big.Int {
+	return new(big.Int).Quo(d.coefficient(), pow10(d.scale))
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.coefficient(), pow10(d.scale)).Float64()
+	return f
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.coefficient()).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// divide returns num / den rounded to an integer with mode.
+func divide(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	sign := num.Sign() * den.Sign()
+	// compare the dropped fraction |r / den| with one half
+	twice := new(big.Int).Abs(r)
+	cmp := twice.Lsh(twice, 1).Cmp(new(big.Int).Abs(den))
+	var away bool
+	switch mode {
+	case HalfEven:
+		away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+	case HalfUp:
+		away = cmp >= 0
+	case HalfDown:
+		away = cmp > 0
+	case Up:
+		away = true
+	case Down:
+		away = false
+	case Ceiling:
+		away = sign > 0
+	case Floor:
+		away = sign < 0
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
@@ -0,0 +1,179 @@
+package decimal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "12.30", want: "12.30"},
+		{input: "-0.05", want: "-0.05"},
+		{input: "+7", want: "7"},
+		{input: "1.5e3", want: "1500"},
+		{input: "1.5e-3", want: "0.0015"},
+		{input: "0.000", want: "0.000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parse(t, tt.input).String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	for _, input := range []string{"", ".", "1.2.3", "1e", "--1", "1-2", "e5", "1e 2"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) should fail", input)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := parse(t, "1.10"), parse(t, "-0.025")
+	cube, err := a.Pow(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{name: "add", got: a.Add(b), want: "1.075"},
+		{name: "sub", got: a.Sub(b), want: "1.125"},
+		{name: "mul", got: a.Mul(b), want: "-0.02750"},
+		{name: "neg", got: b.Neg(), want: "0.025"},
+		{name: "pow", got: cube, want: "1.331000"},
+		{name: "zero value", got: Decimal{}.Add(a), want: "1.10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if a.Cmp(parse(t, "1.1")) != 0 || b.Cmp(a) != -1 {
+		t.Error("comparison ignores the scale")
+	}
+}
+
+func TestRange(t *testing.T) {
+	for _, input := range []string{"1e2000000000", "1e-100001", "1e100001", "0." + strings.Repeat("0", MaxScale) + "1"} {
+		if _, err := Parse(input); !errors.Is(err, ErrRange) {
+			t.Errorf("Parse(%.20q): got %v, want ErrRange", input, err)
+		}
+	}
+	if _, err := Parse("1e100000"); err != nil {
+		t.Errorf("Parse at the limit: %v", err)
+	}
+	for _, pow := range []struct {
+		base string
+		n    int
+	}{
+		{base: "0.1", n: MaxScale + 1},
+		{base: "10", n: 3000000000},
+		{base: "2.5", n: 1 << 30},
+	} {
+		if _, err := parse(t, pow.base).Pow(pow.n); !errors.Is(err, ErrRange) {
+			t.Errorf("%s ** %d: got %v, want ErrRange", pow.base, pow.n, err)
+		}
+	}
+	if d, err := parse(t, "1").Pow(3000000000); err != nil || d.String() != "1" {
+		t.Errorf("1 ** 3000000000: got %v, %v", d, err)
+	}
+}
+
+func TestQuo(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		scale int
+		mode  RoundingMode
+		want  string
+	}{
+		{a: "10", b: "3", scale: 4, mode: HalfEven, want: "3.3333"},
+		{a: "2", b: "3", scale: 2, mode: Down, want: "0.66"},
+		{a: "-2", b: "3", scale: 2, mode: Floor, want: "-0.67"},
+		{a: "-2", b: "3", scale: 2, mode: Ceiling, want: "-0.66"},
+		{a: "1", b: "8", scale: 2, mode: HalfEven, want: "0.12"},
+		{a: "3", b: "8", scale: 2, mode: HalfEven, want: "0.38"},
+		{a: "1", b: "8", scale: 2, mode: HalfDown, want: "0.12"},
+		{a: "1", b: "8", scale: 2, mode: HalfUp, want: "0.13"},
+		{a: "-1", b: "8", scale: 2, mode: HalfUp, want: "-0.13"},
+		{a: "1", b: "-3", scale: 0, mode: Up, want: "-1"},
+		{a: "0.5", b: "0.25", scale: 0, mode: Down, want: "2"},
+		{a: "1.23", b: "10", scale: 1, mode: HalfEven, want: "0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b+" "+tt.mode.String(), func(t *testing.T) {
+			got, err := parse(t, tt.a).Quo(parse(t, tt.b), tt.scale, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if _, err := parse(t, "1").Quo(Decimal{}, 2, HalfEven); err != ErrDivisionByZero {
+		t.Errorf("got %v, want %v", err, ErrDivisionByZero)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		input string
+		scale int
+		mode  RoundingMode
+		want  string
+	}{
+		{input: "2.345", scale: 2, mode: HalfEven, want: "2.34"},
+		{input: "2.355", scale: 2, mode: HalfEven, want: "2.36"},
+		{input: "2.345", scale: 2, mode: HalfUp, want: "2.35"},
+		{input: "-2.345", scale: 2, mode: HalfUp, want: "-2.35"},
+		{input: "2.345", scale: 2, mode: HalfDown, want: "2.34"},
+		{input: "2.341", scale: 2, mode: Up, want: "2.35"},
+		{input: "-2.349", scale: 2, mode: Down, want: "-2.34"},
+		{input: "-2.341", scale: 2, mode: Floor, want: "-2.35"},
+		{input: "1250", scale: -2, mode: HalfEven, want: "1200"},
+		{input: "1.5", scale: 3, mode: HalfEven, want: "1.500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input+" "+tt.mode.String(), func(t *testing.T) {
+			if got := parse(t, tt.input).Round(tt.scale, tt.mode).String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMod(t *testing.T) {
+	got, _ := parse(t, "-7.5").Mod(parse(t, "2"))
+	if got.String() != "0.5" {
+		t.Errorf("got %s, want 0.5", got)
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, mode := range []RoundingMode{HalfEven, HalfUp, HalfDown, Up, Down, Ceiling, Floor} {
+		if got, ok := ParseRoundingMode(mode.String()); !ok || got != mode {
+			t.Errorf("got %v, want %v", got, mode)
+		}
+	}
+	if _, ok := ParseRoundingMode("nearest"); ok {
+		t.Error("unknown rounding modes should be rejected")
+	}
+}
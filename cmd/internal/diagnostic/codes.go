@@ -0,0 +1,39 @@
+package diagnostic
+
+// Codes identify each kind of diagnostic. They are stable, so they can be
+// looked up and referred to, and a code is never reused for another kind.
+const (
+	// Lexical errors.
+	UnclosedString        = "E0001"
+	InvalidEscape         = "E0002"
+	UnclosedInterpolation = "E0003"
+	UnclosedComment       = "E0004"
+	InvalidNumber         = "E0005"
+
+	// Syntax errors.
+	UnexpectedSyntax   = "E0100"
+	MissingToken       = "E0101"
+	ReservedName       = "E0102"
+	InvalidAssignment  = "E0103"
+	EmptyInterpolation = "E0104"
+
+	// Runtime errors.
+	TypeMismatch          = "E0200"
+	UnsupportedType       = "E0201"
+	UnsupportedOperator   = "E0202"
+	DivisionByZero        = "E0203"
+	Undefined             = "E0204"
+	UndefinedAssignment   = "E0205"
+	ConstantAssignment    = "E0206"
+	ConstantRedeclaration = "E0207"
+	UnsupportedOperation  = "E0208"
+	ArgumentCount         = "E0209"
+	CallDepthExceeded     = "E0210"
+	OutsideLoop           = "E0211"
+	NotIterable           = "E0212"
+	IndexOutOfRange       = "E0213"
+	NotIndexable          = "E0214"
+	UnhashableKey         = "E0215"
+	KeyNotFound           = "E0216"
+	NoMatch               = "E0217"
+)
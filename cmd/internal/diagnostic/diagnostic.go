@@ -0,0 +1,200 @@
+// Package diagnostic describes the errors found in Atom programs and renders
+// them the way compilers do, with the offending source line underlined.
+package diagnostic
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/token"
+)
+
+// Diagnostic is an error located at a span of the source. Pos is the start
+// of the span and End the offset of its last rune; a diagnostic raised at
+// run time without a known location has an invalid Pos.
+type Diagnostic struct {
+	Code    string
+	Message string
+	Pos     token.Position
+	End     int
+	Notes   []string
+}
+
+// New returns a diagnostic spanning the runes from pos to the offset end.
+func New(code string, pos token.Position, end int, format string, args ...any) Diagnostic {
+	return Diagnostic{Code: code, Message: fmt.Sprintf(format, args...), Pos: pos, End: end}
+}
+
+// WithNote returns a copy of d with a help note added.
+func (d Diagnostic) WithNote(format string, args ...any) Diagnostic {
+	d.Notes = append(d.Notes[:len(d.Notes):len(d.Notes)], fmt.Sprintf(format, args...))
+	return d
+}
+
+// At returns a copy of d located at the span from pos to the offset end.
+func (d Diagnostic) At(pos token.Position, end int) Diagnostic {
+	d.Pos, d.End = pos, end
+	return d
+}
+
+// Error formats d on a single line.
+func (d Diagnostic) Error() string {
+	if !d.Pos.IsValid() {
+		return "error: " + d.Message
+	}
+	return fmt.Sprintf("error: %s at line: %d, column: %d", d.Message, d.Pos.Line, d.Pos.Column)
+}
+
+const (
+	bold = "1"
+	red  = "1;31"
+	blue = "1;34"
+	cyan = "1;36"
+)
+
+// Render formats d with its code, location, the line of source it points
+// at with the span underlined, and its notes. ANSI colours are used when
+// color is set. The source is the whole input that d.Pos refers to; the
+// snippet is left out when it is nil.
+func (d Diagnostic) Render(source []rune, color bool) string {
+	paint := painter(color)
+	var b strings.Builder
+	b.WriteString(paint(red, "error["+d.Code+"]") + paint(bold, ": "+d.Message) + "\n")
+	line, ok := sourceLine(source, d.Pos)
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Pos.Line)))
+	if d.Pos.IsValid() {
+		b.WriteString(gutter + paint(blue, "--> ") + d.Pos.String() + "\n")
+	}
+	if ok {
+		// the underline copies the tabs of the line so it stays aligned
+		var indent strings.Builder
+		for _, ch := range line[:d.Pos.Column-1] {
+			if ch == '\t' {
+				indent.WriteRune('\t')
+			} else {
+				indent.WriteRune(' ')
+			}
+		}
+		// spans running past the end of the line are cut at its end
+		width := d.End - d.Pos.Offset + 1
+		if rest := len(line) - d.Pos.Column + 1; width > rest {
+			width = rest
+		}
+		if width < 1 {
+			width = 1
+		}
+		b.WriteString(gutter + paint(blue, " |") + "\n")
+		b.WriteString(paint(blue, strconv.Itoa(d.Pos.Line)+" |") + " " + string(line) + "\n")
+		b.WriteString(gutter + paint(blue, " |") + " " + indent.String() + paint(red, strings.Repeat("^", width)) + "\n")
+	}
+	for _, note := range d.Notes {
+		b.WriteString(gutter + paint(blue, " = ") + paint(cyan, "help") + ": " + note + "\n")
+	}
+	return b.String()
+}
+
+// Frame is a call to an Atom function that was active when an error was
+// raised: the function called and where it was called from.
+type Frame struct {
+	Function string
+	Pos      token.Position
+}
+
+// maxRepeatedFrames is how many identical consecutive entries a traceback
+// shows before it summarises the rest, as deep recursion would otherwise
+// bury the error under thousands of them.
+const maxRepeatedFrames = 3
+
+// Traceback formats the calls in frames, innermost first, that were active
+// when an error was raised at pos, listing the most recent call last. Each
+// entry shows where the program was in a function, followed by that line of
+// its source, which source returns by file name.
+func Traceback(frames []Frame, pos token.Position, source func(file string) []rune, color bool) string {
+	paint := painter(color)
+	type entry struct {
+		function string
+		pos      token.Position
+	}
+	entries := make([]entry, 0, len(frames)+1)
+	for i := len(frames) - 1; i >= 0; i-- {
+		caller := "<program>"
+		if i+1 < len(frames) {
+			caller = frames[i+1].Function
+		}
+		entries = append(entries, entry{caller, frames[i].Pos})
+	}
+	if len(frames) > 0 {
+		entries = append(entries, entry{frames[0].Function, pos})
+	}
+	var b strings.Builder
+	b.WriteString(paint(bold, "traceback (most recent call last):") + "\n")
+	repeats := 0
+	summarise := func() {
+		if repeats >= maxRepeatedFrames {
+			fmt.Fprintf(&b, "  [previous entry repeated %d more times]\n", repeats-maxRepeatedFrames+1)
+		}
+	}
+	for i, e := range entries {
+		if i > 0 && e == entries[i-1] {
+			repeats++
+		} else {
+			summarise()
+			repeats = 0
+		}
+		if repeats >= maxRepeatedFrames {
+			continue
+		}
+		b.WriteString("  " + paint(blue, e.pos.String()) + " in " + e.function + "\n")
+		if line, ok := sourceLine(source(e.pos.File), e.pos); ok {
+			b.WriteString("    " + strings.TrimSpace(string(line)) + "\n")
+		}
+	}
+	summarise()
+	return b.String()
+}
+
+// painter returns a function that wraps text in an ANSI style when color
+// is set and leaves it alone otherwise.
+func painter(color bool) func(style, s string) string {
+	return func(style, s string) string {
+		if !color {
+			return s
+		}
+		return "\x1b[" + style + "m" + s + "\x1b[0m"
+	}
+}
+
+// sourceLine returns the line of source that pos is on, without its line
+// terminator.
+func sourceLine(source []rune, pos token.Position) ([]rune, bool) {
+	if source == nil || !pos.IsValid() || pos.Offset > len(source) {
+		return nil, false
+	}
+	start := pos.Offset - pos.Column + 1
+	if start < 0 {
+		return nil, false
+	}
+	end := start
+	for end < len(source) && source[end] != '\n' {
+		end++
+	}
+	if end > start && source[end-1] == '\r' {
+		end--
+	}
+	if pos.Column-1 > end-start {
+		return nil, false
+	}
+	return source[start:end], true
+}
+
+// Colorful reports whether output written to f should be coloured: f has to
+// be a terminal and the NO_COLOR environment variable unset.
+func Colorful(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
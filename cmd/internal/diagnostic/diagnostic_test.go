@@ -0,0 +1,205 @@
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/token"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		diagnostic Diagnostic
+		color      bool
+		want       string
+	}{
+		{
+			name:       "span",
+			source:     "let a = 1\nlet b = a / 0\n",
+			diagnostic: New(DivisionByZero, token.Position{File: "main.om", Line: 2, Column: 9, Offset: 18}, 22, "division by zero"),
+			want: "error[E0203]: division by zero\n" +
+				" --> main.om:2:9\n" +
+				"  |\n" +
+				"2 | let b = a / 0\n" +
+				"  |         ^^^^^\n",
+		},
+		{
+			name:   "notes",
+			source: `"\q"`,
+			diagnostic: New(InvalidEscape, token.Position{Line: 1, Column: 2, Offset: 1}, 2, "invalid escape sequence '\\q'").
+				WithNote("first").WithNote("second"),
+			want: "error[E0002]: invalid escape sequence '\\q'\n" +
+				" --> 1:2\n" +
+				"  |\n" +
+				"1 | \"\\q\"\n" +
+				"  |  ^^\n" +
+				"  = help: first\n" +
+				"  = help: second\n",
+		},
+		{
+			name:       "tabs keep the underline aligned",
+			source:     "\tx\t+ y",
+			diagnostic: New(Undefined, token.Position{Line: 1, Column: 6, Offset: 5}, 5, "undefined symbol 'y'"),
+			want: "error[E0204]: undefined symbol 'y'\n" +
+				" --> 1:6\n" +
+				"  |\n" +
+				"1 | \tx\t+ y\n" +
+				"  | \t \t  ^\n",
+		},
+		{
+			name:       "crlf and multi-byte runes",
+			source:     "let größe = 1\r\ngröße + \"m\"\r\n",
+			diagnostic: New(TypeMismatch, token.Position{Line: 2, Column: 1, Offset: 15}, 25, "mismatch types"),
+			want: "error[E0200]: mismatch types\n" +
+				" --> 2:1\n" +
+				"  |\n" +
+				"2 | größe + \"m\"\n" +
+				"  | ^^^^^^^^^^^\n",
+		},
+		{
+			name:       "span cut at the end of the line",
+			source:     "\"abc\ndef",
+			diagnostic: New(UnclosedString, token.Position{Line: 1, Column: 1, Offset: 0}, 7, "unclosed string"),
+			want: "error[E0001]: unclosed string\n" +
+				" --> 1:1\n" +
+				"  |\n" +
+				"1 | \"abc\n" +
+				"  | ^^^^\n",
+		},
+		{
+			name:       "at the end of the input",
+			source:     "if",
+			diagnostic: New(MissingToken, token.Position{Line: 1, Column: 3, Offset: 2}, 2, "missing 'do'"),
+			want: "error[E0101]: missing 'do'\n" +
+				" --> 1:3\n" +
+				"  |\n" +
+				"1 | if\n" +
+				"  |   ^\n",
+		},
+		{
+			name:       "without a location",
+			source:     "break",
+			diagnostic: Diagnostic{Code: OutsideLoop, Message: "'break' outside of a loop"},
+			want:       "error[E0211]: 'break' outside of a loop\n",
+		},
+		{
+			name:       "without the source",
+			diagnostic: New(Undefined, token.Position{Line: 12, Column: 3, Offset: 99}, 99, "undefined symbol 'x'"),
+			want: "error[E0204]: undefined symbol 'x'\n" +
+				"  --> 12:3\n",
+		},
+		{
+			name:       "colour",
+			source:     "x",
+			diagnostic: New(Undefined, token.Position{Line: 1, Column: 1, Offset: 0}, 0, "undefined symbol 'x'"),
+			color:      true,
+			want: "\x1b[1;31merror[E0204]\x1b[0m\x1b[1m: undefined symbol 'x'\x1b[0m\n" +
+				" \x1b[1;34m--> \x1b[0m1:1\n" +
+				" \x1b[1;34m |\x1b[0m\n" +
+				"\x1b[1;34m1 |\x1b[0m x\n" +
+				" \x1b[1;34m |\x1b[0m \x1b[1;31m^\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source []rune
+			if tt.source != "" {
+				source = []rune(tt.source)
+			}
+			if got := tt.diagnostic.Render(source, tt.color); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	located := New(Undefined, token.Position{Line: 3, Column: 7, Offset: 20}, 21, "undefined symbol '%s'", "xs")
+	if got, want := located.Error(), "error: undefined symbol 'xs' at line: 3, column: 7"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	unlocated := Diagnostic{Code: DivisionByZero, Message: "division by zero"}
+	if got, want := unlocated.Error(), "error: division by zero"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if moved := unlocated.At(located.Pos, located.End); moved.Pos != located.Pos || moved.End != 21 || moved.Code != DivisionByZero {
+		t.Errorf("got %+v", moved)
+	}
+}
+
+func TestTraceback(t *testing.T) {
+	sources := map[string][]rune{
+		"main.om": []rune("fn f |a| -> a / 0 end\nf(1)\n"),
+		"lib.om":  []rune("fn g |a| -> f(a) end\n"),
+	}
+	source := func(file string) []rune { return sources[file] }
+	tests := []struct {
+		name   string
+		frames []Frame
+		pos    token.Position
+		want   string
+	}{
+		{
+			name:   "single call",
+			frames: []Frame{{Function: "f", Pos: token.Position{File: "main.om", Line: 2, Column: 1, Offset: 22}}},
+			pos:    token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12},
+			want: "traceback (most recent call last):\n" +
+				"  main.om:2:1 in <program>\n" +
+				"    f(1)\n" +
+				"  main.om:1:13 in f\n" +
+				"    fn f |a| -> a / 0 end\n",
+		},
+		{
+			name: "calls across files",
+			frames: []Frame{
+				{Function: "f", Pos: token.Position{File: "lib.om", Line: 1, Column: 13, Offset: 12}},
+				{Function: "g", Pos: token.Position{File: "main.om", Line: 2, Column: 1, Offset: 22}},
+			},
+			pos: token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12},
+			want: "traceback (most recent call last):\n" +
+				"  main.om:2:1 in <program>\n" +
+				"    f(1)\n" +
+				"  lib.om:1:13 in g\n" +
+				"    fn g |a| -> f(a) end\n" +
+				"  main.om:1:13 in f\n" +
+				"    fn f |a| -> a / 0 end\n",
+		},
+		{
+			name: "repeated entries are summarised",
+			frames: []Frame{
+				{Function: "f", Pos: token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12}},
+				{Function: "f", Pos: token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12}},
+				{Function: "f", Pos: token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12}},
+				{Function: "f", Pos: token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12}},
+				{Function: "f", Pos: token.Position{File: "main.om", Line: 2, Column: 1, Offset: 22}},
+			},
+			pos: token.Position{File: "main.om", Line: 1, Column: 13, Offset: 12},
+			want: "traceback (most recent call last):\n" +
+				"  main.om:2:1 in <program>\n" +
+				"    f(1)\n" +
+				"  main.om:1:13 in f\n" +
+				"    fn f |a| -> a / 0 end\n" +
+				"  main.om:1:13 in f\n" +
+				"    fn f |a| -> a / 0 end\n" +
+				"  main.om:1:13 in f\n" +
+				"    fn f |a| -> a / 0 end\n" +
+				"  [previous entry repeated 2 more times]\n",
+		},
+		{
+			name:   "without the source",
+			frames: []Frame{{Function: "h", Pos: token.Position{File: "other.om", Line: 4, Column: 2, Offset: 30}}},
+			pos:    token.Position{File: "other.om", Line: 1, Column: 1, Offset: 0},
+			want: "traceback (most recent call last):\n" +
+				"  other.om:4:2 in <program>\n" +
+				"  other.om:1:1 in h\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Traceback(tt.frames, tt.pos, source, false); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,30 +1,119 @@
 package env
 
-import "github.com/iamBharatManral/atom.git/cmd/internal/result"
+import (
+	"errors"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
+)
+
+var (
+	ErrUndefined = errors.New("undefined symbol")
+	ErrConstant  = errors.New("constant symbol")
+)
 
 type Environment struct {
-	symbols map[string]result.Result
+	symbols   map[string]value.Value
+	constants map[string]bool
+	parent    *Environment
+	runtime   *Runtime
+}
+
+// Runtime holds the state of a running program, which all of its scopes
+// share.
+type Runtime struct {
+	// CallDepth counts the function calls in progress.
+	CallDepth int
+	Decimal   DecimalContext
 }
 
+// DecimalContext holds the scale and rounding mode of decimal results that
+// cannot be exact, such as 1d / 3.
+type DecimalContext struct {
+	Scale    int
+	Rounding decimal.RoundingMode
+}
+
+// New creates the outermost scope of a program, with a runtime of its own.
 func New() *Environment {
 	return &Environment{
-		symbols: make(map[string]result.Result),
+		symbols:   make(map[string]value.Value),
+		constants: make(map[string]bool),
+		runtime:   &Runtime{Decimal: DecimalContext{Scale: 28, Rounding: decimal.HalfEven}},
 	}
 }
 
-func (e *Environment) Get(symbol string) (result.Result, bool) {
+// NewEnclosed creates a scope whose lookups fall back to parent when a
+// symbol is not defined locally. It shares the runtime of parent.
+func NewEnclosed(parent *Environment) *Environment {
+	return &Environment{
+		symbols:   make(map[string]value.Value),
+		constants: make(map[string]bool),
+		parent:    parent,
+		runtime:   parent.runtime,
+	}
+}
+
+func (e *Environment) Get(symbol string) (value.Value, bool) {
+	if value, ok := e.symbols[symbol]; ok {
+		return value, ok
+	}
+	if e.parent != nil {
+		return e.parent.Get(symbol)
+	}
+	return nil, false
+}
+
+func (e *Environment) GetLocal(symbol string) (value.Value, bool) {
 	value, ok := e.symbols[symbol]
 	return value, ok
 }
 
-func (e *Environment) Set(symbol string, result result.Result) {
-	e.symbols[symbol] = result
+func (e *Environment) Set(symbol string, v value.Value) {
+	e.symbols[symbol] = v
+}
+
+// SetConstant defines symbol in the current scope and forbids any later
+// assignment to it.
+func (e *Environment) SetConstant(symbol string, v value.Value) {
+	e.symbols[symbol] = v
+	e.constants[symbol] = true
+}
+
+func (e *Environment) IsConstant(symbol string) bool {
+	return e.constants[symbol]
+}
+
+// Assign updates symbol in the nearest scope that defines it. It returns
+// ErrUndefined when no scope holds the symbol and ErrConstant when the
+// binding was declared with const.
+func (e *Environment) Assign(symbol string, v value.Value) error {
+	for scope := e; scope != nil; scope = scope.parent {
+		if _, ok := scope.symbols[symbol]; !ok {
+			continue
+		}
+		if scope.constants[symbol] {
+			return ErrConstant
+		}
+		scope.symbols[symbol] = v
+		return nil
+	}
+	return ErrUndefined
+}
+
+func (e *Environment) Runtime() *Runtime {
+	return e.runtime
+}
+
+func (e *Environment) Parent() *Environment {
+	return e.parent
 }
 
-func (e *Environment) Symbols() map[string]result.Result {
+func (e *Environment) Symbols() map[string]value.Value {
 	return e.symbols
 }
 
 func (e *Environment) Delete(symbol string) {
 	delete(e.symbols, symbol)
+	delete(e.constants, symbol)
 }
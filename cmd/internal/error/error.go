@@ -3,67 +3,97 @@ package error
 import (
 	"fmt"
 
-	"github.com/iamBharatManral/atom.git/cmd/internal/result"
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
 )
 
-func TypeMismatchError(first, second any) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintf("error: mismatch types %v(%T) and %v(%T)", first, first, second, second),
-	}
+// newError returns a runtime error. Its location is filled in by the
+// interpreter once it is known which node raised it.
+func newError(code string, format string, args ...any) *value.Error {
+	return &value.Error{Diagnostic: diagnostic.Diagnostic{Code: code, Message: fmt.Sprintf(format, args...)}}
 }
 
-func UnsupportedTypeError(left any, operator string) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintf("error: unsupported type '%T' for %s", left.(result.Result).Type, operator),
-	}
+func TypeMismatchError(first, second value.Value) *value.Error {
+	return newError(diagnostic.TypeMismatch, "mismatch types %v(%s) and %v(%s)", first, first.Kind(), second, second.Kind())
 }
 
-func UnsupportedOperatorError(operator string) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintf("error: unsupported operator %s", operator),
-	}
+func UnsupportedTypeError(left value.Value, operator string) *value.Error {
+	return newError(diagnostic.UnsupportedType, "unsupported type '%s' for %s", left.Kind(), operator)
 }
 
-func UnsupportedTokensError() result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintln("error: unsupported tokens"),
-	}
+func UnsupportedOperatorError(operator string) *value.Error {
+	return newError(diagnostic.UnsupportedOperator, "unsupported operator %s", operator)
 }
 
-func DivisonByZeroError() result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: "error: division by zero",
-	}
+func UnsupportedTokensError() *value.Error {
+	return newError(diagnostic.UnsupportedOperator, "unsupported tokens")
 }
 
-func SyntaxError(message string) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: message,
-	}
+func DivisonByZeroError() *value.Error {
+	return newError(diagnostic.DivisionByZero, "division by zero")
 }
 
-func UndefinedError(symbol string) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintf("error: undefined symbol '%s'", symbol),
-	}
+func SyntaxError(message string) *value.Error {
+	return newError(diagnostic.UnexpectedSyntax, "%s", message)
 }
 
-func UnsupportedOperation(msg string) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintf("error: %s", msg),
-	}
+func UndefinedError(symbol string) *value.Error {
+	return newError(diagnostic.Undefined, "undefined symbol '%s'", symbol)
 }
-func NotEnoughArguments(msg string) result.Result {
-	return result.Result{
-		Type:  "error",
-		Value: fmt.Sprintf("error: %s", msg),
-	}
+
+func UndefinedAssignmentError(symbol string) *value.Error {
+	return newError(diagnostic.UndefinedAssignment, "cannot assign to undefined symbol '%s', declare it first with 'let'", symbol)
+}
+
+func ConstantAssignmentError(symbol string) *value.Error {
+	return newError(diagnostic.ConstantAssignment, "cannot assign to constant '%s'", symbol)
+}
+
+func ConstantRedeclarationError(symbol string) *value.Error {
+	return newError(diagnostic.ConstantRedeclaration, "constant '%s' is already defined", symbol)
+}
+
+// NumberOutOfRangeError reports a number too large to be represented or
+// computed.
+func NumberOutOfRangeError(msg string) *value.Error {
+	return newError(diagnostic.InvalidNumber, "%s", msg)
+}
+
+func UnsupportedOperation(msg string) *value.Error {
+	return newError(diagnostic.UnsupportedOperation, "%s", msg)
+}
+func NotEnoughArguments(msg string) *value.Error {
+	return newError(diagnostic.ArgumentCount, "%s", msg)
+}
+
+func CallDepthExceededError(limit int) *value.Error {
+	return newError(diagnostic.CallDepthExceeded, "maximum call depth of %d exceeded", limit)
+}
+
+func OutsideLoopError(keyword string) *value.Error {
+	return newError(diagnostic.OutsideLoop, "'%s' outside of a loop", keyword)
+}
+
+func NotIterableError(v value.Value) *value.Error {
+	return newError(diagnostic.NotIterable, "%v(%s) is not iterable", v, v.Kind())
+}
+
+func IndexOutOfRangeError(index, length int) *value.Error {
+	return newError(diagnostic.IndexOutOfRange, "index %d out of range for length %d", index, length)
+}
+
+func NotIndexableError(v value.Value) *value.Error {
+	return newError(diagnostic.NotIndexable, "%v(%s) is not indexable", v, v.Kind())
+}
+
+func UnhashableKeyError(key value.Value) *value.Error {
+	return newError(diagnostic.UnhashableKey, "%v(%s) cannot be used as a map key", key, key.Kind())
+}
+
+func KeyNotFoundError(key string) *value.Error {
+	return newError(diagnostic.KeyNotFound, "key %s not found", key)
+}
+
+func NoMatchError(value string) *value.Error {
+	return newError(diagnostic.NoMatch, "no match clause matches %s", value)
 }
@@ -7,19 +7,22 @@ import (
 	"runtime/debug"
 	"strings"
 
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/env"
 	"github.com/iamBharatManral/atom.git/cmd/internal/interpreter"
 	"github.com/iamBharatManral/atom.git/cmd/internal/lexer"
 	"github.com/iamBharatManral/atom.git/cmd/internal/parser"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
 )
 
 func Execute(filename string, stack bool) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("panic: unexpected thing happenned! must be the issue with syntax!")
+			fmt.Printf("internal error: %v\n", r)
 			if stack {
 				debug.PrintStack()
 			}
+			os.Exit(1)
 		}
 	}()
 	fileInfo := strings.Split(filename, ".")
@@ -32,15 +35,26 @@ func Execute(filename string, stack bool) {
 		log.Printf("error: file %s, does not exists", filename)
 		os.Exit(1)
 	}
-	lexer := lexer.New([]rune(string(input)))
+	source := []rune(string(input))
+	lexer := lexer.NewFile(filename, source)
 	parser := parser.New(lexer)
 	program := parser.Parse()
+	color := diagnostic.Colorful(os.Stdout)
+	if len(parser.Errors) > 0 {
+		for _, err := range parser.Errors {
+			fmt.Print(err.Render(source, color))
+		}
+		os.Exit(1)
+	}
 	env := env.New()
-	result := interpreter.Eval(program, env)
-	if result.Type == "error" {
-		log.Fatal(result.Value)
-	} else if result.Type == "" {
-		return
+	for _, stmt := range program.Body {
+		result, err := interpreter.Eval(stmt, env)
+		if err != nil {
+			fmt.Print(err.Render(func(string) []rune { return source }, color))
+			os.Exit(1)
+		}
+		if _, ok := result.(value.Nil); !ok {
+			fmt.Println(result)
+		}
 	}
-	fmt.Println(result.Value)
 }
@@ -0,0 +1,275 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+	"github.com/iamBharatManral/atom.git/cmd/internal/env"
+	"github.com/iamBharatManral/atom.git/cmd/internal/error"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
+)
+
+// builtin implements a builtin function. It is given the runtime of the
+// program calling it, which holds the decimal context.
+type builtin struct {
+	arity int
+	fn    func(runtime *env.Runtime, args []value.Value) (value.Value, *value.Error)
+}
+
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"len":    {arity: 1, fn: builtinLen},
+		"keys":   {arity: 1, fn: builtinKeys},
+		"values": {arity: 1, fn: builtinValues},
+		"delete": {arity: 2, fn: builtinDelete},
+		"int":    {arity: 1, fn: builtinInt},
+		"float":  {arity: 1, fn: builtinFloat},
+		"round":  {arity: -1, fn: builtinRound},
+
+		"decimal":         {arity: 1, fn: builtinDecimal},
+		"decimal_context": {arity: -1, fn: builtinDecimalContext},
+	}
+}
+
+// lookupBuiltin returns the builtin called name, bound to the runtime of ev.
+func lookupBuiltin(name string, ev *env.Environment) (value.Builtin, bool) {
+	b, ok := builtins[name]
+	if !ok {
+		return value.Builtin{}, false
+	}
+	runtime := ev.Runtime()
+	return value.Builtin{Name: name, Arity: b.arity, Fn: func(args []value.Value) (value.Value, *value.Error) {
+		return b.fn(runtime, args)
+	}}, true
+}
+
+func callBuiltin(builtin value.Builtin, arguments []ast.Expression, env *env.Environment) (value.Value, value.Signal) {
+	if builtin.Arity >= 0 && len(arguments) != builtin.Arity {
+		return nil, error.NotEnoughArguments(fmt.Sprintf("arguments count mismatch. require: %d, got: %d", builtin.Arity, len(arguments)))
+	}
+	args := make([]value.Value, len(arguments))
+	for i, stmt := range arguments {
+		arg, sig := evaluate(stmt, env)
+		if sig != nil {
+			return nil, sig
+		}
+		args[i] = arg
+	}
+	return signalled(builtin.Fn(args))
+}
+
+func builtinLen(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	switch v := args[0].(type) {
+	case *value.List:
+		return value.Int(len(v.Elements)), nil
+	case value.Tuple:
+		return value.Int(len(v.Elements)), nil
+	case value.Str:
+		return value.Int(utf8.RuneCountInString(string(v))), nil
+	case value.Range:
+		n, ok := v.Len()
+		if !ok {
+			return nil, error.UnsupportedOperation(fmt.Sprintf("len() of %v does not fit in an int", v))
+		}
+		return value.Int(n), nil
+	case *value.Map:
+		return value.Int(v.Len()), nil
+	}
+	return nil, error.UnsupportedOperation(fmt.Sprintf("len() is not supported for %v(%s)", args[0], args[0].Kind()))
+}
+
+func builtinKeys(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	m, ok := args[0].(*value.Map)
+	if !ok {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("keys() is not supported for %v(%s)", args[0], args[0].Kind()))
+	}
+	return &value.List{Elements: m.Keys()}, nil
+}
+
+func builtinValues(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	m, ok := args[0].(*value.Map)
+	if !ok {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("values() is not supported for %v(%s)", args[0], args[0].Kind()))
+	}
+	values := make([]value.Value, 0, m.Len())
+	for _, key := range m.Keys() {
+		v, _ := m.Get(key.(value.Hashable))
+		values = append(values, v)
+	}
+	return &value.List{Elements: values}, nil
+}
+
+// builtinDelete removes a key from a map and reports whether it was there.
+func builtinDelete(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	m, ok := args[0].(*value.Map)
+	if !ok {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("delete() is not supported for %v(%s)", args[0], args[0].Kind()))
+	}
+	key, ok := args[1].(value.Hashable)
+	if !ok {
+		return nil, error.UnhashableKeyError(args[1])
+	}
+	return value.Bool(m.Delete(key)), nil
+}
+
+// builtinInt converts a number, a numeric string or a boolean to an
+// integer, truncating floats toward zero.
+func builtinInt(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	switch v := args[0].(type) {
+	case value.Int, value.BigInt:
+		return v, nil
+	case value.Float:
+		return truncateFloat("int", float64(v))
+	case value.Decimal:
+		return value.NormalizeInt(v.Int()), nil
+	case value.Bool:
+		if v {
+			return value.Int(1), nil
+		}
+		return value.Int(0), nil
+	case value.Str:
+		n, ok := new(big.Int).SetString(strings.TrimSpace(string(v)), 0)
+		if !ok {
+			return nil, error.UnsupportedOperation(fmt.Sprintf("int() cannot convert %s to an integer", value.Inspect(v)))
+		}
+		return value.NormalizeInt(n), nil
+	}
+	return nil, error.UnsupportedOperation(fmt.Sprintf("int() is not supported for %v(%s)", args[0], args[0].Kind()))
+}
+
+// builtinFloat converts a number, a numeric string or a boolean to a float.
+func builtinFloat(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	switch v := args[0].(type) {
+	case value.Int, value.BigInt, value.Float, value.Decimal:
+		return value.Float(value.ToFloat(v)), nil
+	case value.Bool:
+		if v {
+			return value.Float(1.0), nil
+		}
+		return value.Float(0.0), nil
+	case value.Str:
+		f, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+		if err != nil {
+			return nil, error.UnsupportedOperation(fmt.Sprintf("float() cannot convert %s to a float", value.Inspect(v)))
+		}
+		return value.Float(f), nil
+	}
+	return nil, error.UnsupportedOperation(fmt.Sprintf("float() is not supported for %v(%s)", args[0], args[0].Kind()))
+}
+
+// builtinRound rounds a number half away from zero, to an integer with one
+// argument or to the given number of decimal places with two. Decimals are
+// rounded with the rounding mode of the decimal context instead.
+func builtinRound(runtime *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, error.NotEnoughArguments(fmt.Sprintf("arguments count mismatch. require: 1 or 2, got: %d", len(args)))
+	}
+	if !value.IsNumber(args[0]) {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("round() is not supported for %v(%s)", args[0], args[0].Kind()))
+	}
+	if d, ok := args[0].(value.Decimal); ok && len(args) == 1 {
+		return value.NormalizeInt(d.Round(0, runtime.Decimal.Rounding).Int()), nil
+	}
+	if len(args) == 1 {
+		if f, ok := args[0].(value.Float); ok {
+			return truncateFloat("round", math.Round(float64(f)))
+		}
+		return args[0], nil
+	}
+	places, ok := args[1].(value.Int)
+	if !ok {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("round() places must be an integer, got %v(%s)", args[1], args[1].Kind()))
+	}
+	if d, ok := args[0].(value.Decimal); ok {
+		if places > decimal.MaxScale || places < -decimal.MaxScale {
+			return nil, error.NumberOutOfRangeError(fmt.Sprintf("round() places %v are out of range", places))
+		}
+		return value.Decimal{Decimal: d.Round(int(places), runtime.Decimal.Rounding)}, nil
+	}
+	f, isFloat := args[0].(value.Float)
+	if !isFloat && places >= 0 {
+		return args[0], nil
+	}
+	scale := math.Pow(10, float64(places))
+	rounded := math.Round(value.ToFloat(args[0])*scale) / scale
+	if !isFloat {
+		return truncateFloat("round", rounded)
+	}
+	if math.IsInf(rounded, 0) || math.IsNaN(rounded) {
+		return f, nil
+	}
+	return value.Float(rounded), nil
+}
+
+// builtinDecimal converts an integer, a float or a numeric string to a
+// decimal. Floats convert to the shortest decimal that reads back as the
+// same float, so decimal(0.1) is exactly 0.1.
+func builtinDecimal(_ *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	switch v := args[0].(type) {
+	case value.Decimal:
+		return v, nil
+	case value.Int, value.BigInt:
+		return value.ToDecimal(v), nil
+	case value.Float:
+		if math.IsInf(float64(v), 0) || math.IsNaN(float64(v)) {
+			return nil, error.UnsupportedOperation(fmt.Sprintf("decimal() cannot convert %v to a decimal", v))
+		}
+		d, _ := decimal.Parse(strconv.FormatFloat(float64(v), 'g', -1, 64))
+		return value.Decimal{Decimal: d}, nil
+	case value.Str:
+		d, err := decimal.Parse(strings.ReplaceAll(strings.TrimSpace(string(v)), "_", ""))
+		if errors.Is(err, decimal.ErrRange) {
+			return nil, error.NumberOutOfRangeError(fmt.Sprintf("decimal() of %s is out of range", value.Inspect(v)))
+		}
+		if err != nil {
+			return nil, error.UnsupportedOperation(fmt.Sprintf("decimal() cannot convert %s to a decimal", value.Inspect(v)))
+		}
+		return value.Decimal{Decimal: d}, nil
+	}
+	return nil, error.UnsupportedOperation(fmt.Sprintf("decimal() is not supported for %v(%s)", args[0], args[0].Kind()))
+}
+
+// builtinDecimalContext sets the number of digits kept after the decimal
+// point by inexact decimal results and, optionally, the rounding mode used
+// for them and by round(). It returns the previous settings as a tuple so
+// they can be restored.
+func builtinDecimalContext(runtime *env.Runtime, args []value.Value) (value.Value, *value.Error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, error.NotEnoughArguments(fmt.Sprintf("arguments count mismatch. require: 1 or 2, got: %d", len(args)))
+	}
+	scale, ok := args[0].(value.Int)
+	if !ok || scale < 0 {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("decimal_context() scale must be a non-negative integer, got %v(%s)", args[0], args[0].Kind()))
+	}
+	if scale > decimal.MaxScale {
+		return nil, error.NumberOutOfRangeError(fmt.Sprintf("decimal_context() scale %v is larger than %d", scale, decimal.MaxScale))
+	}
+	rounding := runtime.Decimal.Rounding
+	if len(args) == 2 {
+		name, _ := args[1].(value.Str)
+		if rounding, ok = decimal.ParseRoundingMode(string(name)); !ok {
+			return nil, error.UnsupportedOperation(fmt.Sprintf("decimal_context() unknown rounding mode %s", value.Inspect(args[1])))
+		}
+	}
+	previous := value.Tuple{Elements: []value.Value{value.Int(runtime.Decimal.Scale), value.Str(runtime.Decimal.Rounding.String())}}
+	runtime.Decimal.Scale, runtime.Decimal.Rounding = int(scale), rounding
+	return previous, nil
+}
+
+// truncateFloat converts f to an integer, dropping its fractional part.
+func truncateFloat(name string, f float64) (value.Value, *value.Error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("%s() cannot convert %v to an integer", name, f))
+	}
+	n, _ := big.NewFloat(f).Int(nil)
+	return value.NormalizeInt(n), nil
+}
@@ -2,23 +2,65 @@ package interpreter
 
 import (
 	"fmt"
-	"reflect"
-	"strconv"
+	"math/big"
+	"strings"
 
 	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/env"
 	"github.com/iamBharatManral/atom.git/cmd/internal/error"
-	"github.com/iamBharatManral/atom.git/cmd/internal/result"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
 )
 
-func Eval(node ast.Statement, env *env.Environment) result.Result {
+// Eval evaluates a statement of a program. A return outside of a function
+// evaluates to the returned value, while a break or a continue outside of
+// a loop is an error.
+func Eval(node ast.Node, env *env.Environment) (value.Value, *value.Error) {
+	v, sig := evaluate(node, env)
+	switch sig := sig.(type) {
+	case nil:
+		return v, nil
+	case value.Return:
+		return sig.Value, nil
+	case value.Break:
+		return nil, locate(error.OutsideLoopError("break"), node)
+	case value.Continue:
+		return nil, locate(error.OutsideLoopError("continue"), node)
+	case *value.Error:
+		return nil, sig
+	}
+	panic(fmt.Sprintf("unexpected signal %T", sig))
+}
+
+// evaluate evaluates node. A runtime error is located at the innermost
+// node whose evaluation raised it.
+func evaluate(node ast.Node, env *env.Environment) (value.Value, value.Signal) {
+	v, sig := eval(node, env)
+	if err, ok := sig.(*value.Error); ok {
+		return nil, locate(err, node)
+	}
+	return v, sig
+}
+
+// locate places a runtime error that has no location yet at node.
+func locate(err *value.Error, node ast.Node) *value.Error {
+	if _, program := node.(ast.Program); program || node == nil || err.Pos.IsValid() || !node.Pos().IsValid() {
+		return err
+	}
+	return &value.Error{Diagnostic: err.At(node.Pos(), node.End()), Frames: err.Frames}
+}
+
+func eval(node ast.Node, env *env.Environment) (value.Value, value.Signal) {
 	switch node := node.(type) {
 	case ast.Program:
-		return evalStatements(node.Body, env)
+		return evalBlock(node.Body, env)
 	case ast.Literal:
-		return evalLiteral(node)
+		return signalled(evalLiteral(node))
 	case ast.BinaryExpression:
 		return evalBinaryExpression(node, env)
+	case ast.UnaryExpression:
+		return evalUnaryExpression(node, env)
 	case ast.LetStatement:
 		return evalLetStatement(node, env)
 	case ast.Identifier:
@@ -29,622 +71,754 @@ func Eval(node ast.Statement, env *env.Environment) result.Result {
 		return evalIfExpression(node, env)
 	case ast.IfElseBlock:
 		return evalIfElseExpression(node, env)
+	case ast.BlockStatement:
+		return evalBlockStatement(node, env)
 	case ast.FunctionExpression:
+		if node.Name.Value == "" {
+			return newFunction(node, env, ""), nil
+		}
 		return evalFunctionExpression(node, env, "")
 	case ast.FunctionEvaluation:
 		return evalFunction(node, env)
 	case ast.ReturnStatement:
 		return evalReturnStatement(node, env)
+	case ast.WhileExpression:
+		return evalWhileExpression(node, env)
+	case ast.ForExpression:
+		return evalForExpression(node, env)
+	case ast.RangeExpression:
+		return evalRangeExpression(node, env)
+	case ast.ListLiteral:
+		return evalListLiteral(node, env)
+	case ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+	case ast.TupleLiteral:
+		return evalTupleLiteral(node, env)
+	case ast.MapLiteral:
+		return evalMapLiteral(node, env)
+	case ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case ast.IndexExpression:
+		return evalIndexExpression(node, env)
+	case ast.IndexAssignmentStatement:
+		return evalIndexAssignment(node, env)
+	case ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case ast.BreakStatement:
+		return evalBreakStatement(node, env)
+	case ast.ContinueStatement:
+		return nil, value.Continue{}
 	default:
-		return error.UnsupportedTokensError()
+		return nil, error.UnsupportedTokensError()
 	}
 }
 
-func evalReturnStatement(node ast.ReturnStatement, ev *env.Environment) result.Result {
-	switch v := node.Value.(type) {
-	case ast.Identifier:
-		return createResult("return", evalIdentifier(v, ev).Value)
-	case ast.Literal:
-		return createResult("return", v.Value)
-	case ast.BinaryExpression:
-		return createResult("return", evalBinaryExpression(v, ev))
-	case ast.FunctionEvaluation:
-		return createResult("return", evalFunction(v, ev))
+// maxCallDepth bounds how deeply Atom functions may recurse before the
+// interpreter gives up instead of exhausting the Go stack.
+const maxCallDepth = 10000
+
+func evalReturnStatement(node ast.ReturnStatement, ev *env.Environment) (value.Value, value.Signal) {
+	if node.Value == nil {
+		return nil, value.Return{Value: value.Nil{}}
+	}
+	v, sig := evaluate(node.Value, ev)
+	if sig != nil {
+		return nil, sig
 	}
-	return result.Result{}
+	return nil, value.Return{Value: v}
 }
 
-func evalFunction(node ast.FunctionEvaluation, ev *env.Environment) result.Result {
-	localEnv := env.New()
-	fnName := node.Name.Value
-	var funcDecl ast.FunctionExpression
-	if fn, ok := ev.Get(fnName); !ok {
-		return error.UndefinedError(fnName)
+func evalFunction(node ast.FunctionEvaluation, ev *env.Environment) (value.Value, value.Signal) {
+	var callee value.Value
+	var sig value.Signal
+	if node.Callee != nil {
+		callee, sig = evaluate(node.Callee, ev)
 	} else {
-		funcDecl = fn.Value.(ast.FunctionExpression)
+		callee, sig = evalIdentifier(node.Name, ev)
 	}
-	if fnName != node.Name.Value {
-		return error.UndefinedError(node.Name.Value)
+	if sig != nil {
+		return nil, sig
 	}
-	if len(node.Arguments) != len(funcDecl.Parameters) {
-		return error.NotEnoughArguments(fmt.Sprintf("error: arguments count mismatch. require: %d, got: %d", len(funcDecl.Parameters), len(node.Arguments)))
+	switch fn := callee.(type) {
+	case value.Function:
+		return callFunction(fn, node, ev)
+	case value.Builtin:
+		return callBuiltin(fn, node.Arguments, ev)
 	}
-	localEnv.Set(fnName, createResult("function declaration", funcDecl))
-	for i, stmt := range node.Arguments {
-		switch stmt := stmt.(type) {
-		case ast.Identifier:
-			result := evalIdentifier(stmt, ev)
-			if result.Type == "error" {
-				return error.UndefinedError(stmt.Value)
-			}
-			localEnv.Set(funcDecl.Parameters[i].Value, createResult("identifier", result.Value))
-		case ast.Literal:
-			localEnv.Set(funcDecl.Parameters[i].Value, createResult("identifier", stmt.Value))
-		case ast.BinaryExpression:
-			result := evalBinaryExpression(stmt, ev)
-			if result.Type == "error" {
-				return error.UnsupportedOperation("error: invalid binary expression")
-			}
-			localEnv.Set(funcDecl.Parameters[i].Value, createResult("binary", result.Value))
-		}
-	}
-	var results []result.Result
-	for _, stmt := range funcDecl.Body {
-		if _, ok := stmt.(ast.ReturnStatement); ok {
-			return Eval(stmt, localEnv)
-		} else {
-			res := Eval(stmt, localEnv)
-			if res.Type == "return" {
-				return createResult("literal", res.Value)
-			} else {
-				results = append(results, Eval(stmt, localEnv))
-			}
-		}
-	}
-	if len(results) > 0 {
-		return results[len(results)-1]
+	if node.Callee == nil {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("'%s' is not a function", node.Name.Value))
 	}
-	return result.Result{}
+	return nil, error.UnsupportedOperation(fmt.Sprintf("%v(%s) is not a function", callee, callee.Kind()))
 }
 
-func evalFunctionExpression(stmt ast.FunctionExpression, env *env.Environment, fnName string) result.Result {
+// callFunction calls fn with the arguments of call. An error raised by its
+// body records the call as a frame of its traceback.
+func callFunction(fn value.Function, call ast.FunctionEvaluation, ev *env.Environment) (value.Value, value.Signal) {
+	funcDecl, arguments := fn.Declaration, call.Arguments
+	if len(arguments) != len(funcDecl.Parameters) {
+		return nil, error.NotEnoughArguments(fmt.Sprintf("arguments count mismatch. require: %d, got: %d", len(funcDecl.Parameters), len(arguments)))
+	}
+	localEnv := env.NewEnclosed(fn.Env.(*env.Environment))
+	for i, stmt := range arguments {
+		arg, sig := evaluate(stmt, ev)
+		if sig != nil {
+			return nil, sig
+		}
+		localEnv.Set(funcDecl.Parameters[i].Value, arg)
+	}
+	runtime := localEnv.Runtime()
+	if runtime.CallDepth >= maxCallDepth {
+		return nil, error.CallDepthExceededError(maxCallDepth)
+	}
+	runtime.CallDepth++
+	defer func() { runtime.CallDepth-- }()
+	v, sig := evalBlock(funcDecl.Body, localEnv)
+	switch sig := sig.(type) {
+	case value.Return:
+		return sig.Value, nil
+	case value.Break:
+		return nil, error.OutsideLoopError("break")
+	case value.Continue:
+		return nil, error.OutsideLoopError("continue")
+	case *value.Error:
+		name := funcDecl.Name.Value
+		if name == "" {
+			name = "<fn>"
+		}
+		sig.Frames = append(sig.Frames, diagnostic.Frame{Function: name, Pos: call.Pos()})
+	}
+	return v, sig
+}
+
+func evalFunctionExpression(stmt ast.FunctionExpression, env *env.Environment, fnName string) (value.Value, value.Signal) {
 	name := stmt.Name.Value
 	if name == "" {
 		name = fnName
 	}
-	if _, ok := env.Get(name); ok {
-		return error.UnsupportedOperation(fmt.Sprintf("symbol '%s' is already defined", name))
+	if _, ok := env.GetLocal(name); ok {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("symbol '%s' is already defined", name))
 	}
-	env.Set(name, createResult("fn", stmt))
-	return createResult("function declaration", "()")
+	env.Set(name, newFunction(stmt, env, name))
+	return value.Nil{}, nil
 }
 
-func evalAssignment(stmt ast.AssignmentStatement, env *env.Environment) result.Result {
-	id := stmt.Left.Value
-	if _, ok := env.Get(string(id)); !ok {
-		return error.UndefinedError(id)
+func newFunction(stmt ast.FunctionExpression, env *env.Environment, name string) value.Function {
+	if stmt.Name.Value == "" {
+		stmt.Name.Value = name
 	}
-	return error.UnsupportedOperation("re-assignment is not supported")
+	return value.Function{Declaration: stmt, Env: env}
 }
 
-func evalRHS(stmt ast.LetStatement, env *env.Environment) result.Result {
+func evalAssignment(stmt ast.AssignmentStatement, ev *env.Environment) (value.Value, value.Signal) {
 	id := stmt.Left.Value
-	switch right := stmt.Right.(type) {
-	case ast.Literal:
-		env.Set(id, createResult("literal", right.Value))
-	case ast.Identifier:
-		r := evalIdentifier(stmt.Right.(ast.Identifier), env)
-		if r.Type == "error" {
-			return r
-		}
-		env.Set(id, createResult("identifier", r.Value))
-	case ast.BinaryExpression:
-		r := evalBinaryExpression(stmt.Right.(ast.BinaryExpression), env)
-		if r.Type == "error" {
-			return r
-		}
-		env.Set(id, createResult("BinaryExpression", r.Value))
-	case ast.FunctionExpression:
-		return evalFunctionExpression(stmt.Right.(ast.FunctionExpression), env, id)
-	case ast.IfBlock:
-		r := evalIfExpression(right, env)
-		if r.Type == "error" {
-			return r
-		}
-		env.Set(id, createResult("IfExpression", r.Value))
-	case ast.IfElseBlock:
-		r := evalIfElseExpression(right, env)
-		if r.Type == "error" {
-			return r
-		}
-		env.Set(id, createResult("IfElseExpression", r.Value))
-	default:
-		return error.UnsupportedTokensError()
+	v, sig := evalRHS(stmt.Right, ev, id)
+	if sig != nil {
+		return nil, sig
 	}
-	return result.Result{}
-
-}
-func evalStatements(stmts []ast.Statement, env *env.Environment) result.Result {
-	var completeResult string
-	for i := range stmts {
-		result := Eval(stmts[i], env)
-		if result.Type == "error" {
-			return result
-		} else if result.Type == "" || result.Value == "()" {
-			continue
-		}
-		completeResult += fmt.Sprintf("%v\n", result.Value)
+	switch ev.Assign(id, v) {
+	case env.ErrUndefined:
+		return nil, error.UndefinedAssignmentError(id)
+	case env.ErrConstant:
+		return nil, error.ConstantAssignmentError(id)
 	}
-	return result.Result{
-		Type:  "result",
-		Value: completeResult,
+	return value.Nil{}, nil
+}
+
+// evalRHS evaluates the right side of a binding. Function literals are
+// turned into values named after the symbol they are bound to.
+func evalRHS(right ast.Expression, env *env.Environment, id string) (value.Value, value.Signal) {
+	if fn, ok := right.(ast.FunctionExpression); ok {
+		return newFunction(fn, env, id), nil
 	}
+	return evaluate(right, env)
 }
 
-func evalLetStatement(stmt ast.LetStatement, env *env.Environment) result.Result {
-	return evalRHS(stmt, env)
+func evalLetStatement(stmt ast.LetStatement, env *env.Environment) (value.Value, value.Signal) {
+	id := stmt.Left.Value
+	if env.IsConstant(id) {
+		return nil, error.ConstantRedeclarationError(id)
+	}
+	v, sig := evalRHS(stmt.Right, env, id)
+	if sig != nil {
+		return nil, sig
+	}
+	if stmt.Constant {
+		env.SetConstant(id, v)
+	} else {
+		env.Set(id, v)
+	}
+	return value.Nil{}, nil
 }
 
-func evalUniOperator(op string, val any) any {
+// evalUniOperator applies a unary operator: '-' to a number or '!' to a
+// boolean. An empty operator leaves the value as it is.
+func evalUniOperator(op string, v value.Value) (value.Value, *value.Error) {
 	switch op {
+	case "":
+		return v, nil
 	case "-":
-		switch reflect.TypeOf(val).Kind() {
-		case reflect.Int:
-			return -val.(int)
-		case reflect.Float64:
-			return -val.(float64)
+		switch v := v.(type) {
+		case value.Int:
+			return negateInt(int(v)), nil
+		case value.BigInt:
+			return value.NormalizeInt(new(big.Int).Neg(v.Int)), nil
+		case value.Float:
+			return -v, nil
+		case value.Decimal:
+			return value.Decimal{Decimal: v.Neg()}, nil
 		}
 	case "!":
-		switch val {
-		case "true":
-			return false
-		case "false":
-			return true
+		if b, ok := v.(value.Bool); ok {
+			return !b, nil
 		}
-	default:
-		return val
 	}
-	return val
+	return nil, error.UnsupportedTypeError(v, op)
 }
-func evalIdentifier(stmt ast.Identifier, env *env.Environment) result.Result {
-	finalVal := evalUniOperator(stmt.UnaryOp, stmt.Value)
-	switch stmt.Value {
-	case "true", "false":
-		if reflect.TypeOf(finalVal).Kind() == reflect.String {
-			finalVal, _ = strconv.ParseBool(finalVal.(string))
-		}
-		return createResult("literal", finalVal)
-	default:
-		id := stmt.Value
-		if result, ok := env.Get(id); ok {
-			return createResult("identifier", result.Value)
-		}
-		return error.UndefinedError(id)
+
+// evalUnaryExpression applies '-' to a number or '!' to a boolean.
+func evalUnaryExpression(stmt ast.UnaryExpression, env *env.Environment) (value.Value, value.Signal) {
+	v, sig := evaluate(stmt.Value, env)
+	if sig != nil {
+		return nil, sig
 	}
-}
-func evalLiteral(l ast.Literal) result.Result {
-	finalValue := evalUniOperator(l.UnaryOp, l.Value)
-	return createResult("literal", finalValue)
+	return signalled(evalUniOperator(stmt.Operator, v))
 }
 
-func evalIfExpression(stmt ast.IfBlock, env *env.Environment) result.Result {
-	test := stmt.Test
-	var testResult result.Result
-	switch test := test.(type) {
-	case ast.BinaryExpression:
-		testResult = evalBinaryExpression(test, env)
-	case ast.Identifier:
-		val, _ := strconv.ParseBool(test.Value)
-		testResult = createResult("boolean", val)
-	}
-	if testResult.Value == true {
-		finalResult := Eval(stmt.Consequent, env)
-		if finalResult.Type == "return" {
-			return finalResult
-		}
-		return createResult("conditional", finalResult.Value)
+// signalled converts the result of a function that can only fail with an
+// error to one that can raise any signal, keeping a nil error nil.
+func signalled(v value.Value, err *value.Error) (value.Value, value.Signal) {
+	if err != nil {
+		return nil, err
 	}
-	return result.Result{}
-
+	return v, nil
 }
 
-func evalIfElseExpression(stmt ast.IfElseBlock, env *env.Environment) result.Result {
-	test := stmt.Test
-	var testResult result.Result
-	switch test := test.(type) {
-	case ast.BinaryExpression:
-		testResult = evalBinaryExpression(test, env)
-	case ast.Identifier:
-		val, _ := strconv.ParseBool(test.Value)
-		testResult = createResult("boolean", val)
+func evalIdentifier(stmt ast.Identifier, env *env.Environment) (value.Value, value.Signal) {
+	id := stmt.Value
+	switch id {
+	case "true", "false":
+		return signalled(evalUniOperator(stmt.UnaryOp, value.Bool(id == "true")))
 	}
-	if testResult.Value == true {
-		finalResult := Eval(stmt.Consequent, env)
-		return createResult("conditional", finalResult.Value)
-	} else {
-		finalResult := Eval(stmt.Alternate, env)
-		return createResult("conditional", finalResult.Value)
+	if v, ok := env.Get(id); ok {
+		return signalled(evalUniOperator(stmt.UnaryOp, v))
 	}
-
-}
-
-func typeAsString(v any) string {
-	return fmt.Sprintf("%v", v)
+	if builtin, ok := lookupBuiltin(id, env); ok {
+		return signalled(evalUniOperator(stmt.UnaryOp, builtin))
+	}
+	return nil, error.UndefinedError(id)
 }
 
-func evalBinaryExpression(stmt ast.BinaryExpression, env *env.Environment) result.Result {
-	var tempLeft, tempRight any
-	if _, ok := stmt.Left.(ast.BinaryExpression); ok {
-		tempLeft = evalBinaryExpression(stmt.Left.(ast.BinaryExpression), env)
-	} else {
-		tempLeft = Eval(stmt.Left, env)
-	}
-	if _, ok := stmt.Right.(ast.BinaryExpression); ok {
-		tempRight = evalBinaryExpression(stmt.Right.(ast.BinaryExpression), env)
-	} else {
-		tempRight = Eval(stmt.Right, env)
-	}
-	left := createResult(tempLeft.(result.Result).Type, tempLeft.(result.Result).Value)
-	right := createResult(tempRight.(result.Result).Type, tempRight.(result.Result).Value)
-	switch stmt.Operator {
-	case "+":
-		return evalAddition(left, right)
-	case "-":
-		return evalSubtraction(left, right)
-	case "*":
-		return evalMultiplication(left, right)
-	case "/":
-		return evalDivision(left, right)
-	case "<":
-		return evalLessThan(left, right)
-	case "<=":
-		return evalLessThanEqual(left, right)
-	case ">":
-		return evalGreaterThan(left, right)
-	case ">=":
-		return evalGreaterThanEqual(left, right)
-	case "!=":
-		return evalNotEqual(left, right)
-	case "==":
-		return evalEqualEqual(left, right)
-	case "and":
-		return evalLogicalAnd(left, right)
-	case "or":
-		return evalLogicalOr(left, right)
-	case "%":
-		return evalMod(left, right)
+func evalLiteral(l ast.Literal) (value.Value, *value.Error) {
+	var v value.Value
+	switch literal := l.Value.(type) {
+	case int:
+		v = value.Int(literal)
+	case *big.Int:
+		v = value.NormalizeInt(literal)
+	case float64:
+		v = value.Float(literal)
+	case decimal.Decimal:
+		v = value.Decimal{Decimal: literal}
+	case string:
+		v = value.Str(literal)
 	default:
-		return error.UnsupportedOperatorError(stmt.Operator)
+		panic(fmt.Sprintf("unexpected literal %T", l.Value))
 	}
+	return evalUniOperator(l.UnaryOp, v)
 }
 
-func evalLogicalAnd(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
-	}
-	if right.Type == "error" {
-		return right
+// evalIfExpression runs the consequent in a scope of its own, so that a
+// let in an inline branch does not leak, just as in a block.
+func evalIfExpression(stmt ast.IfBlock, ev *env.Environment) (value.Value, value.Signal) {
+	test, sig := evalCondition(stmt.Test, ev)
+	if sig != nil {
+		return nil, sig
 	}
-	switch left := left.Value.(type) {
-	case bool:
-		if right, ok := right.Value.(bool); ok {
-			return createResult("bool", left && right)
-		}
-		return error.TypeMismatchError(left, right.Value)
+	if test {
+		return evaluate(stmt.Consequent, env.NewEnclosed(ev))
 	}
-	return error.UnsupportedTypeError(left, "and")
-
+	return value.Nil{}, nil
 }
 
-func evalLogicalOr(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalIfElseExpression(stmt ast.IfElseBlock, ev *env.Environment) (value.Value, value.Signal) {
+	test, sig := evalCondition(stmt.Test, ev)
+	if sig != nil {
+		return nil, sig
 	}
-	if right.Type == "error" {
-		return right
+	if test {
+		return evaluate(stmt.Consequent, env.NewEnclosed(ev))
 	}
-	switch left := left.Value.(type) {
-	case bool:
-		if right, ok := right.Value.(bool); ok {
-			return createResult("bool", left || right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	}
-	return error.UnsupportedTypeError(left, "or")
+	return evaluate(stmt.Alternate, env.NewEnclosed(ev))
+}
 
+func evalBlockStatement(stmt ast.BlockStatement, ev *env.Environment) (value.Value, value.Signal) {
+	return evalBlock(stmt.Body, env.NewEnclosed(ev))
 }
 
-func evalEqualEqual(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
-	}
-	if right.Type == "error" {
-		return right
-	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left == right)
+func evalWhileExpression(stmt ast.WhileExpression, ev *env.Environment) (value.Value, value.Signal) {
+	var last value.Value = value.Nil{}
+	for {
+		test, sig := evalCondition(stmt.Test, ev)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left == right)
+		if !test {
+			break
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left == right)
+		stop, sig := evalIteration(stmt.Body, env.NewEnclosed(ev), &last)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case bool:
-		if right, ok := right.Value.(bool); ok {
-			return createResult("bool", left == right)
+		if stop {
+			break
 		}
-		return error.TypeMismatchError(left, right.Value)
 	}
-	return error.UnsupportedTypeError(left, "==")
+	return last, nil
+}
 
+func evalForExpression(stmt ast.ForExpression, ev *env.Environment) (value.Value, value.Signal) {
+	iterable, sig := evaluate(stmt.Iterable, ev)
+	if sig != nil {
+		return nil, sig
+	}
+	var last value.Value = value.Nil{}
+	ok := iterate(iterable, func(item value.Value) bool {
+		loopEnv := env.NewEnclosed(ev)
+		loopEnv.Set(stmt.Variable.Value, item)
+		var stop bool
+		stop, sig = evalIteration(stmt.Body, loopEnv, &last)
+		return !stop
+	})
+	if !ok {
+		return nil, error.NotIterableError(iterable)
+	}
+	if sig != nil {
+		return nil, sig
+	}
+	return last, nil
 }
 
-func evalNotEqual(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
-	}
-	if right.Type == "error" {
-		return right
+// evalIteration evaluates one pass over a loop body and records in last
+// the value the loop evaluates to so far. It reports whether the loop has
+// to stop, and the signal the loop has to hand to its caller if any.
+func evalIteration(body []ast.Statement, env *env.Environment, last *value.Value) (bool, value.Signal) {
+	v, sig := evalBlock(body, env)
+	switch sig := sig.(type) {
+	case nil:
+		*last = v
+		return false, nil
+	case value.Break:
+		if sig.Value != nil {
+			*last = sig.Value
+		}
+		return true, nil
+	case value.Continue:
+		return false, nil
+	}
+	return true, sig
+}
+
+func evalRangeExpression(stmt ast.RangeExpression, env *env.Environment) (value.Value, value.Signal) {
+	bounds := []ast.Expression{stmt.From, stmt.To}
+	if stmt.Step != nil {
+		bounds = append(bounds, stmt.Step)
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left != right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left != right)
+	var values []int
+	for _, bound := range bounds {
+		v, sig := evaluate(bound, env)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left != right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	case bool:
-		if right, ok := right.Value.(bool); ok {
-			return createResult("bool", left == right)
+		n, err := toInt(v, "range bound")
+		if err != nil {
+			return nil, err
 		}
-		return error.TypeMismatchError(left, right.Value)
+		values = append(values, n)
 	}
-
-	return error.UnsupportedTypeError(left, "!=")
+	rng := value.Range{From: values[0], To: values[1], Step: 1, Inclusive: stmt.Inclusive}
+	if len(values) == 3 {
+		rng.Step = values[2]
+	} else if rng.From > rng.To {
+		rng.Step = -1
+	}
+	if rng.Step == 0 {
+		return nil, error.UnsupportedOperation("range step cannot be zero")
+	}
+	return rng, nil
 }
 
-func evalGreaterThanEqual(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
-	}
-	if right.Type == "error" {
-		return right
+func evalListLiteral(stmt ast.ListLiteral, env *env.Environment) (value.Value, value.Signal) {
+	elements, sig := evalElements(stmt.Elements, env)
+	if sig != nil {
+		return nil, sig
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left >= right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left >= right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left >= right)
+	return &value.List{Elements: elements}, nil
+}
+
+func evalInterpolatedString(stmt ast.InterpolatedString, env *env.Environment) (value.Value, value.Signal) {
+	var sb strings.Builder
+	for _, part := range stmt.Parts {
+		v, sig := evaluate(part, env)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
+		sb.WriteString(v.String())
 	}
-	return error.UnsupportedTypeError(left, ">=")
+	return value.Str(sb.String()), nil
 }
 
-func evalGreaterThan(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalTupleLiteral(stmt ast.TupleLiteral, env *env.Environment) (value.Value, value.Signal) {
+	elements, sig := evalElements(stmt.Elements, env)
+	if sig != nil {
+		return nil, sig
 	}
-	if right.Type == "error" {
-		return right
+	return value.Tuple{Elements: elements}, nil
+}
+
+func evalElements(exprs []ast.Expression, env *env.Environment) ([]value.Value, value.Signal) {
+	elements := make([]value.Value, len(exprs))
+	for i, element := range exprs {
+		v, sig := evaluate(element, env)
+		if sig != nil {
+			return nil, sig
+		}
+		elements[i] = v
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left > right)
+	return elements, nil
+}
+
+func evalMapLiteral(stmt ast.MapLiteral, env *env.Environment) (value.Value, value.Signal) {
+	m := value.NewMap()
+	for i := range stmt.Keys {
+		key, sig := evaluate(stmt.Keys[i], env)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left > right)
+		hashable, ok := key.(value.Hashable)
+		if !ok {
+			return nil, error.UnhashableKeyError(key)
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left > right)
+		v, sig := evaluate(stmt.Values[i], env)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
+		m.Set(hashable, v)
 	}
-	return error.UnsupportedTypeError(left, ">")
+	return m, nil
 }
 
-func evalLessThanEqual(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalIndexAssignment(stmt ast.IndexAssignmentStatement, env *env.Environment) (value.Value, value.Signal) {
+	left, sig := evaluate(stmt.Left.Left, env)
+	if sig != nil {
+		return nil, sig
 	}
-	if right.Type == "error" {
-		return right
+	index, sig := evaluate(stmt.Left.Index, env)
+	if sig != nil {
+		return nil, sig
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left <= right)
+	right, sig := evalRHS(stmt.Right, env, "")
+	if sig != nil {
+		return nil, sig
+	}
+	switch collection := left.(type) {
+	case *value.List:
+		i, err := toInt(index, "index")
+		if err != nil {
+			return nil, err
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left <= right)
+		pos, ok := normalizeIndex(i, len(collection.Elements))
+		if !ok {
+			return nil, error.IndexOutOfRangeError(i, len(collection.Elements))
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left <= right)
+		collection.Elements[pos] = right
+	case *value.Map:
+		key, ok := index.(value.Hashable)
+		if !ok {
+			return nil, error.UnhashableKeyError(index)
 		}
-		return error.TypeMismatchError(left, right.Value)
+		collection.Set(key, right)
+	default:
+		return nil, error.UnsupportedOperation(fmt.Sprintf("%v(%s) does not support item assignment", left, left.Kind()))
 	}
-	return error.UnsupportedTypeError(left, "<=")
+	return value.Nil{}, nil
 }
 
-func evalLessThan(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalIndexExpression(stmt ast.IndexExpression, env *env.Environment) (value.Value, value.Signal) {
+	left, sig := evaluate(stmt.Left, env)
+	if sig != nil {
+		return nil, sig
+	}
+	index, sig := evaluate(stmt.Index, env)
+	if sig != nil {
+		return nil, sig
+	}
+	if m, ok := left.(*value.Map); ok {
+		key, ok := index.(value.Hashable)
+		if !ok {
+			return nil, error.UnhashableKeyError(index)
+		}
+		v, ok := m.Get(key)
+		if !ok {
+			return nil, error.KeyNotFoundError(value.Inspect(index))
+		}
+		return v, nil
+	}
+	var elements []value.Value
+	switch collection := left.(type) {
+	case *value.List:
+		elements = collection.Elements
+	case value.Tuple:
+		elements = collection.Elements
+	case value.Str:
+		runes := []rune(collection)
+		elements = make([]value.Value, len(runes))
+		for i, r := range runes {
+			elements[i] = value.Str(r)
+		}
+	default:
+		return nil, error.NotIndexableError(left)
 	}
-	if right.Type == "error" {
-		return right
+	i, err := toInt(index, "index")
+	if err != nil {
+		return nil, err
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left < right)
+	pos, ok := normalizeIndex(i, len(elements))
+	if !ok {
+		return nil, error.IndexOutOfRangeError(i, len(elements))
+	}
+	return elements[pos], nil
+}
+
+func evalSliceExpression(stmt ast.SliceExpression, env *env.Environment) (value.Value, value.Signal) {
+	left, sig := evaluate(stmt.Left, env)
+	if sig != nil {
+		return nil, sig
+	}
+	var length int
+	switch collection := left.(type) {
+	case *value.List:
+		length = len(collection.Elements)
+	case value.Str:
+		length = len([]rune(collection))
+	default:
+		return nil, error.NotIndexableError(left)
+	}
+	bounds := []int{0, length}
+	for i, bound := range []ast.Expression{stmt.Low, stmt.High} {
+		if bound == nil {
+			continue
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left < right)
+		v, sig := evaluate(bound, env)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left < right)
+		n, err := toInt(v, "slice bound")
+		if err != nil {
+			return nil, err
 		}
-		return error.TypeMismatchError(left, right.Value)
+		bounds[i] = n
+	}
+	low, high := sliceBounds(bounds[0], bounds[1], length)
+	if list, ok := left.(*value.List); ok {
+		elements := make([]value.Value, high-low)
+		copy(elements, list.Elements[low:high])
+		return &value.List{Elements: elements}, nil
 	}
-	return error.UnsupportedTypeError(left, "<")
+	return value.Str([]rune(left.(value.Str))[low:high]), nil
 }
 
-func evalAddition(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalBreakStatement(stmt ast.BreakStatement, env *env.Environment) (value.Value, value.Signal) {
+	if stmt.Value == nil {
+		return nil, value.Break{}
 	}
-	if right.Type == "error" {
-		return right
+	v, sig := evaluate(stmt.Value, env)
+	if sig != nil {
+		return nil, sig
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left+right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left+right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	case string:
-		if right, ok := right.Value.(string); ok {
-			return createResult("string", left+right)
+	return nil, value.Break{Value: v}
+}
+
+// evalBlock evaluates statements in order and yields the value of the last
+// one, stopping early on errors and control flow signals.
+func evalBlock(stmts []ast.Statement, env *env.Environment) (value.Value, value.Signal) {
+	var last value.Value = value.Nil{}
+	for _, stmt := range stmts {
+		v, sig := evaluate(stmt, env)
+		if sig != nil {
+			return nil, sig
 		}
-		return error.TypeMismatchError(left, right.Value)
+		last = v
 	}
-	return error.UnsupportedTypeError(left, "+")
+	return last, nil
 }
 
-func evalSubtraction(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalCondition(test ast.Expression, env *env.Environment) (bool, value.Signal) {
+	v, sig := evaluate(test, env)
+	if sig != nil {
+		return false, sig
 	}
-	if right.Type == "error" {
-		return right
+	b, ok := v.(value.Bool)
+	if !ok {
+		return false, error.UnsupportedOperation(fmt.Sprintf("condition must be a boolean, got %v(%s)", v, v.Kind()))
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left-right)
+	return bool(b), nil
+}
+
+func evalBinaryExpression(stmt ast.BinaryExpression, env *env.Environment) (value.Value, value.Signal) {
+	left, sig := evaluate(stmt.Left, env)
+	if sig != nil {
+		return nil, sig
+	}
+	right, sig := evaluate(stmt.Right, env)
+	if sig != nil {
+		return nil, sig
+	}
+	switch stmt.Operator {
+	case "+":
+		return evalAddition(left, right, env.Runtime().Decimal)
+	case "-", "*", "/", "//", "%", "**":
+		return evalNumericOperator(stmt.Operator, left, right, env.Runtime().Decimal)
+	case "<", "<=", ">", ">=":
+		return evalOrdering(stmt.Operator, left, right)
+	case "==", "!=":
+		return evalEquality(stmt.Operator, left, right)
+	case "and":
+		return evalLogicalAnd(left, right)
+	case "or":
+		return evalLogicalOr(left, right)
+	case "in":
+		return evalMembership(left, right)
+	default:
+		return nil, error.UnsupportedOperatorError(stmt.Operator)
+	}
+}
+
+func evalMembership(left, right value.Value) (value.Value, value.Signal) {
+	switch collection := right.(type) {
+	case *value.Map:
+		key, ok := left.(value.Hashable)
+		if !ok {
+			return nil, error.UnhashableKeyError(left)
+		}
+		_, ok = collection.Get(key)
+		return value.Bool(ok), nil
+	case *value.List:
+		for _, element := range collection.Elements {
+			if left.Equal(element) {
+				return value.Bool(true), nil
+			}
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left-right)
+		return value.Bool(false), nil
+	case value.Str:
+		if left, ok := left.(value.Str); ok {
+			return value.Bool(strings.Contains(string(collection), string(left))), nil
 		}
-		return error.TypeMismatchError(left, right.Value)
+		return nil, error.TypeMismatchError(left, collection)
+	case value.Range:
+		n, ok := left.(value.Int)
+		return value.Bool(ok && collection.Contains(int(n))), nil
 	}
-	return error.UnsupportedTypeError(left, "-")
+	return nil, error.UnsupportedTypeError(right, "in")
 }
 
-func createResult(t string, v any) result.Result {
-	return result.Result{
-		Type:  t,
-		Value: v,
+func evalLogicalAnd(left, right value.Value) (value.Value, value.Signal) {
+	l, ok := left.(value.Bool)
+	if !ok {
+		return nil, error.UnsupportedTypeError(left, "and")
 	}
+	r, ok := right.(value.Bool)
+	if !ok {
+		return nil, error.TypeMismatchError(left, right)
+	}
+	return l && r, nil
 }
 
-func evalMultiplication(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+func evalLogicalOr(left, right value.Value) (value.Value, value.Signal) {
+	l, ok := left.(value.Bool)
+	if !ok {
+		return nil, error.UnsupportedTypeError(left, "or")
 	}
-	if right.Type == "error" {
-		return right
+	r, ok := right.(value.Bool)
+	if !ok {
+		return nil, error.TypeMismatchError(left, right)
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left*right)
+	return l || r, nil
+}
+
+// evalEquality compares two numbers, or two strings, booleans, lists,
+// tuples or maps.
+func evalEquality(operator string, left, right value.Value) (value.Value, value.Signal) {
+	switch left.Kind() {
+	case value.IntKind, value.FloatKind, value.DecimalKind:
+		if _, _, ok := value.Promote(left, right); !ok {
+			return nil, error.TypeMismatchError(left, right)
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left*right)
+	case value.StrKind, value.BoolKind, value.RangeKind, value.ListKind, value.TupleKind, value.MapKind:
+		if left.Kind() != right.Kind() {
+			return nil, error.TypeMismatchError(left, right)
 		}
-		return error.TypeMismatchError(left, right.Value)
+	default:
+		return nil, error.UnsupportedTypeError(left, operator)
 	}
-	return error.UnsupportedTypeError(left, "*")
+	return value.Bool(left.Equal(right) == (operator == "==")), nil
 }
 
-func evalDivision(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
+// evalOrdering compares two numbers or two strings.
+func evalOrdering(operator string, left, right value.Value) (value.Value, value.Signal) {
+	l, ok := left.(value.Ordered)
+	if !ok {
+		return nil, error.UnsupportedTypeError(left, operator)
+	}
+	c, ok := l.Compare(right)
+	if !ok {
+		if _, _, numbers := value.Promote(left, right); numbers {
+			// NaN is neither less than, equal to nor greater than a number
+			return value.Bool(false), nil
+		}
+		return nil, error.TypeMismatchError(left, right)
 	}
-	if right.Type == "error" {
-		return right
+	switch operator {
+	case "<":
+		return value.Bool(c < 0), nil
+	case "<=":
+		return value.Bool(c <= 0), nil
+	case ">":
+		return value.Bool(c > 0), nil
 	}
-	if right.Value == 0 {
-		return error.DivisonByZeroError()
+	return value.Bool(c >= 0), nil
+}
+
+func evalAddition(left, right value.Value, ctx env.DecimalContext) (value.Value, value.Signal) {
+	if l, r, ok := value.Promote(left, right); ok {
+		return evalArithmetic("+", l, r, ctx)
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left/right)
+	switch l := left.(type) {
+	case value.Int, value.BigInt, value.Float, value.Decimal:
+		return nil, error.TypeMismatchError(left, right)
+	case value.Str:
+		if r, ok := right.(value.Str); ok {
+			return l + r, nil
 		}
-		return error.TypeMismatchError(left, right.Value)
-	case float64:
-		if right, ok := right.Value.(float64); ok {
-			return createResult("float", left/right)
+		return nil, error.TypeMismatchError(left, right)
+	case *value.List:
+		if r, ok := right.(*value.List); ok {
+			elements := make([]value.Value, 0, len(l.Elements)+len(r.Elements))
+			elements = append(elements, l.Elements...)
+			elements = append(elements, r.Elements...)
+			return &value.List{Elements: elements}, nil
 		}
-		return error.TypeMismatchError(left, right.Value)
+		return nil, error.TypeMismatchError(left, right)
 	}
-	return error.UnsupportedTypeError(left, "/")
+	return nil, error.UnsupportedTypeError(left, "+")
 }
 
-func evalMod(left, right result.Result) result.Result {
-	if left.Type == "error" {
-		return left
-	}
-	if right.Type == "error" {
-		return right
+// evalNumericOperator evaluates an operator that is only defined on numbers.
+func evalNumericOperator(operator string, left, right value.Value, ctx env.DecimalContext) (value.Value, value.Signal) {
+	if l, r, ok := value.Promote(left, right); ok {
+		return evalArithmetic(operator, l, r, ctx)
 	}
-	switch left := left.Value.(type) {
-	case int:
-		if right, ok := right.Value.(int); ok {
-			return createResult("int", left%right)
-		}
-		return error.TypeMismatchError(left, right.Value)
-	default:
-		return error.UnsupportedTypeError(left, "%")
+	if value.IsNumber(left) {
+		return nil, error.TypeMismatchError(left, right)
 	}
+	return nil, error.UnsupportedTypeError(left, operator)
 }
@@ -1,56 +1,668 @@
 package interpreter
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/env"
 	"github.com/iamBharatManral/atom.git/cmd/internal/lexer"
 	"github.com/iamBharatManral/atom.git/cmd/internal/parser"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
 )
 
 func TestEvaluation(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
-		want  any
+		want  value.Value
 	}{
-		{name: "unary minus operator", want: -1, input: `-1`},
-		{name: "integer in bracket", want: 345, input: `(345)`},
-		{name: "BANG (!) operator", want: false, input: `! true`},
-		{name: "BANG (!) operator in bracket", want: true, input: `(!false)`},
-		{name: "integer", want: 2345, input: `2345`},
-		{name: "binary expression", want: 35, input: `12+23`},
-		{name: "binary expression with brackets", want: 148, input: `(125+23)`},
-		{name: "multiple binary expression with brackets", want: 125, input: `(2 + 23) * 5`},
-		{name: "multiple binary expression - example 2", want: 293, input: `(2 + 23) * 5 + (23 + 45) + 90 + (10)`},
-		{name: "variable declaration", want: nil, input: `let a = 10`},
-		{name: "comparison between integers", want: true, input: `13 > 9`},
-		{name: "mod operator", want: 2, input: `12 % 5`},
-		{name: "equality check", want: true, input: `"hello" == "hello"`},
-		{name: "comparison between floats", want: true, input: `1.2 <= 3.4`},
-		{name: "comparison between strings", want: false, input: `"greater" > "less"`},
-		{name: "if block", want: "greater", input: `if 12 > 10 do "greater"`},
-		{name: "if else block with truthy condition", want: "greater than", input: `if 12 > 10 do "greater than" else "less than"`},
-		{name: "if else block with falsy condition", want: "false", input: `if 10 != 10 do "true" else "false"`},
-		{name: "if else block with true keyword", want: "true", input: `if true do "true"`},
-		{name: "if else block with false keyword", want: "false", input: `if false do "true" else "false"`},
-		{name: "binary expression with logical and", want: false, input: `10 != 10 and 12 > 10`},
-		{name: "binary expression with logical or", want: true, input: `10 > 10 or 10 != 10 or 12 > 7`},
-		{name: "function declaration", want: "()", input: `fn hello|a,b| -> a end`},
+		{name: "unary minus operator", want: value.Int(-1), input: `-1`},
+		{name: "integer in bracket", want: value.Int(345), input: `(345)`},
+		{name: "BANG (!) operator", want: value.Bool(false), input: `! true`},
+		{name: "BANG (!) operator in bracket", want: value.Bool(true), input: `(!false)`},
+		{name: "integer", want: value.Int(2345), input: `2345`},
+		{name: "binary expression", want: value.Int(35), input: `12+23`},
+		{name: "binary expression with brackets", want: value.Int(148), input: `(125+23)`},
+		{name: "multiple binary expression with brackets", want: value.Int(125), input: `(2 + 23) * 5`},
+		{name: "multiple binary expression - example 2", want: value.Int(293), input: `(2 + 23) * 5 + (23 + 45) + 90 + (10)`},
+		{name: "variable declaration", want: value.Nil{}, input: `let a = 10`},
+		{name: "comparison between integers", want: value.Bool(true), input: `13 > 9`},
+		{name: "mod operator", want: value.Int(2), input: `12 % 5`},
+		{name: "equality check", want: value.Bool(true), input: `"hello" == "hello"`},
+		{name: "comparison between floats", want: value.Bool(true), input: `1.2 <= 3.4`},
+		{name: "comparison between strings", want: value.Bool(false), input: `"greater" > "less"`},
+		{name: "if block", want: value.Str("greater"), input: `if 12 > 10 do "greater"`},
+		{name: "if else block with truthy condition", want: value.Str("greater than"), input: `if 12 > 10 do "greater than" else "less than"`},
+		{name: "if else block with falsy condition", want: value.Str("false"), input: `if 10 != 10 do "true" else "false"`},
+		{name: "if else block with true keyword", want: value.Str("true"), input: `if true do "true"`},
+		{name: "if else block with false keyword", want: value.Str("false"), input: `if false do "true" else "false"`},
+		{name: "binary expression with logical and", want: value.Bool(false), input: `10 != 10 and 12 > 10`},
+		{name: "binary expression with logical or", want: value.Bool(true), input: `10 > 10 or 10 != 10 or 12 > 7`},
+		{name: "function declaration", want: value.Nil{}, input: `fn hello|a,b| -> a end`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			lexer := lexer.New([]rune(tt.input))
 			parser := parser.New(lexer)
 			program := parser.Parse()
+			if len(parser.Errors) > 0 {
+				t.Fatal(parser.Errors)
+			}
 			env := env.New()
 			for i := range program.Body {
-				output := Eval(program.Body[i], env)
-				if output.Value != tt.want {
-					t.Errorf("got %+v, want %+v", output, tt.want)
+				output, err := Eval(program.Body[i], env)
+				if err != nil || output != tt.want {
+					t.Errorf("got %#v, %v, want %#v", output, err, tt.want)
 				}
 			}
 		})
 	}
 
 }
+
+func evalProgram(input string) (value.Value, *value.Error) {
+	lexer := lexer.New([]rune(input))
+	parser := parser.New(lexer)
+	program := parser.Parse()
+	if len(parser.Errors) > 0 {
+		return nil, &value.Error{Diagnostic: parser.Errors[0]}
+	}
+	env := env.New()
+	var output value.Value
+	for i := range program.Body {
+		var err *value.Error
+		if output, err = Eval(program.Body[i], env); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+func TestPrograms(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  value.Value
+	}{
+		{name: "function reads global binding", want: value.Int(15), input: `let x = 10
+fn addx |a| -> a + x end
+addx(5)`},
+		{name: "function calls another top-level function", want: value.Int(12), input: `fn double |a| -> a * 2 end
+fn quadruple |a| -> double(double(a)) end
+quadruple(3)`},
+		{name: "closure captures outer parameter", want: value.Int(3), input: `fn outer |a| ->
+fn inner |b| -> a + b end
+inner
+end
+let add = outer(1)
+add(2)`},
+		{name: "mutual recursion", want: value.Bool(true), input: `fn even |n| -> if n == 0 do true else odd(n - 1) end
+fn odd |n| -> if n == 0 do false else even(n - 1) end
+even(10)`},
+		{name: "recursion", want: value.Int(120), input: `fn fact |n| -> if n < 2 do 1 else n * fact(n - 1) end
+fact(5)`},
+		{name: "reassignment", want: value.Int(11), input: `let a = 10
+a = a + 1
+a`},
+		{name: "closure counter", want: value.Int(3), input: `fn counter || ->
+let count = 0
+fn next || ->
+count = count + 1
+count
+end
+next
+end
+let tick = counter()
+tick()
+tick()
+tick()`},
+		{name: "assignment updates enclosing scope", want: value.Int(5), input: `let total = 1
+fn set || -> total = 5 end
+set()
+total`},
+		{name: "constant declaration", want: value.Int(3), input: `const three = 3
+three`},
+		{name: "while loop", want: value.Int(15), input: `let i = 0
+let total = 0
+while i < 5 do
+i = i + 1
+total = total + i
+end
+total`},
+		{name: "while loop with break and continue", want: value.Int(25), input: `let i = 0
+let total = 0
+while true do
+i = i + 1
+if i > 10 do break
+if i % 2 == 0 do continue
+total = total + i
+end
+total`},
+		{name: "while loop as expression", want: value.Int(8), input: `let n = 0
+let found = while n < 100 do
+n = n + 1
+if n * n > 50 do break n
+end
+found`},
+		{name: "return from inside loop", want: value.Int(4), input: `fn first |limit| ->
+let i = 0
+while true do
+i = i + 1
+if i == limit do return i
+end
+end
+first(4)`},
+		{name: "for loop over inclusive range", want: value.Int(55), input: `let total = 0
+for i in 1..10 do
+total = total + i
+end
+total`},
+		{name: "for loop over exclusive range with step", want: value.Int(18), input: `let total = 0
+for i in 0..<10 by 3 do total = total + i end
+total`},
+		{name: "for loop over descending range", want: value.Int(321), input: `let digits = 0
+for i in 3..1 do digits = digits * 10 + i end
+digits`},
+		{name: "for loop over string", want: value.Str("cba"), input: `let reversed = ""
+for ch in "abc" do reversed = ch + reversed end
+reversed`},
+		{name: "for loop with break value", want: value.Int(7), input: `let found = for i in 1..100 do
+if i * i > 40 do break i
+end
+found`},
+		{name: "loop variable does not leak", want: value.Int(1), input: `let i = 1
+for i in 5..6 do i end
+i`},
+		{name: "list indexing", want: value.Int(20), input: `let xs = [10, 20, 30]
+xs[1]`},
+		{name: "list negative indexing", want: value.Int(30), input: `let xs = [10, 20, 30]
+xs[-1]`},
+		{name: "nested list indexing", want: value.Int(4), input: `let grid = [[1, 2], [3, 4]]
+grid[1][1]`},
+		{name: "list slicing", want: value.Bool(true), input: `let xs = [1, 2, 3, 4, 5]
+xs[1:3] == [2, 3] and xs[:2] == [1, 2] and xs[3:] == [4, 5] and xs[-2:] == [4, 5]`},
+		{name: "list concatenation", want: value.Bool(true), input: `[1, 2] + [3] == [1, 2, 3]`},
+		{name: "list deep equality", want: value.Bool(false), input: `[1, [2, 3]] == [1, [2, 4]]`},
+		{name: "list length", want: value.Int(3), input: `len([1, "two", 3.0])`},
+		{name: "string length and indexing", want: value.Str("l"), input: `let word = "hello"
+word[len(word) - 2]`},
+		{name: "string slicing", want: value.Str("ell"), input: `"hello"[1:-1]`},
+		{name: "for loop over list", want: value.Int(6), input: `let total = 0
+for x in [1, 2, 3] do total = total + x end
+total`},
+		{name: "multi-line list literal", want: value.Int(3), input: `let xs = [
+1,
+2,
+3
+]
+len(xs)`},
+		{name: "map lookup", want: value.Str("atom"), input: `let config = {"name": "atom", "version": 1}
+config["name"]`},
+		{name: "map update", want: value.Int(3), input: `let config = {"version": 1}
+config["version"] = config["version"] + 2
+config["version"]`},
+		{name: "map insertion", want: value.Int(2), input: `let counts = {}
+counts["a"] = 1
+counts["b"] = 1
+len(counts)`},
+		{name: "map key membership", want: value.Bool(true), input: `let config = {"debug": false}
+"debug" in config and "verbose" in config == false`},
+		{name: "map iteration in insertion order", want: value.Str("zyx"), input: `let m = {"z": 1, "y": 2}
+m["x"] = 3
+let order = ""
+for key in m do order = order + key end
+order`},
+		{name: "map keys and values", want: value.Bool(true), input: `let m = {"a": 1, "b": 2}
+keys(m) == ["a", "b"] and values(m) == [1, 2]`},
+		{name: "map delete", want: value.Bool(true), input: `let m = {"a": 1, "b": 2}
+delete(m, "a")
+keys(m) == ["b"]`},
+		{name: "range equality", want: value.Bool(true), input: `1..3 == 1..3`},
+		{name: "range inequality", want: value.Bool(true), input: `1..3 != 1..<3`},
+		{name: "map equality ignores order", want: value.Bool(true), input: `{"a": 1, "b": [2]} == {"b": [2], "a": 1}`},
+		{name: "integers compare exactly with floats", want: value.Bool(true), input: `let a = 9007199254740993 != 9007199254740992.0 and 9007199254740993 > 9007199254740992.0
+let b = 99999999999999999999 != 100000000000000000000.0 and 99999999999999999999 < 100000000000000000000.0
+let c = 9007199254740992 == 9007199254740992.0 and 100000000000000000000 == 100000000000000000000.0
+a and b and c`},
+		{name: "map keys hash like they compare", want: value.Bool(true), input: `let m = {9007199254740992.0: "f"}
+!(9007199254740993 in m) and 9007199254740992 in m`},
+		{name: "wide range", want: value.Bool(true), input: `let r = -9223372036854775807..9223372036854775807
+let first = 0
+for n in r do
+  first = n
+  break
+end
+first == -9223372036854775807 and 9223372036854775807 in r and len(0..<9223372036854775807) == 9223372036854775807`},
+		{name: "equal numbers are the same map key", want: value.Bool(true), input: `let m = {1: "a", 2.5d: "c"}
+m[1.0] = "b"
+m[1] + m[2.50d] == "bc" and len(m) == 2`},
+		{name: "list item assignment", want: value.Bool(true), input: `let xs = [1, 2, 3]
+xs[-1] = 30
+xs == [1, 2, 30]`},
+		{name: "nested map update", want: value.Int(5), input: `let db = {"users": {"ann": 4}}
+db["users"]["ann"] = 5
+db["users"]["ann"]`},
+		{name: "anonymous function passed as argument", want: value.Int(42), input: `fn apply |f, x| -> f(x) end
+apply(fn |x| -> x * 2 end, 21)`},
+		{name: "anonymous function returned from function", want: value.Int(3), input: `fn adder |n| -> fn |x| -> x + n end end
+adder(1)(2)`},
+		{name: "anonymous function returned with return", want: value.Int(12), input: `fn scaler |n| ->
+return fn |x| -> x * n end
+end
+let triple = scaler(3)
+triple(4)`},
+		{name: "anonymous functions stored in a list", want: value.Int(50), input: `let ops = [fn |x| -> x + 1 end, fn |x| -> x * 10 end]
+ops[1](5)`},
+		{name: "anonymous function stored in a map", want: value.Int(2), input: `let ops = {"inc": fn |x| -> x + 1 end}
+ops["inc"](1)`},
+		{name: "builtin as value", want: value.Int(2), input: `let size = len
+size([1, 2])`},
+		{name: "list and range membership", want: value.Bool(true), input: `2 in [1, 2, 3] and 4 in 0..10 by 2 and 5 in 0..10 by 2 == false`},
+		{name: "block if with several statements", want: value.Int(30), input: `let x = 10
+if x > 5 do
+  let y = x * 2
+  x = x + y
+end
+x`},
+		{name: "block if evaluates to last statement", want: value.Str("big"), input: `let size = if 100 > 10 do
+  let unit = "big"
+  unit
+else
+  "small"
+end
+size`},
+		{name: "block if locals do not leak", want: value.Int(1), input: `let y = 1
+if true do
+  let y = 2
+  y
+end
+y`},
+		{name: "inline if locals do not leak", want: value.Int(1), input: `let y = 1
+if true do let y = 2
+if false do 0 else let y = 3
+y`},
+		{name: "block if without else is nil when false", want: value.Nil{}, input: `if false do
+  1
+end`},
+		{name: "elif chain", want: value.Str("FizzBuzz,n,Fizz,Buzz"), input: `fn fizzbuzz |n| ->
+if n % 15 == 0 do
+  "FizzBuzz"
+elif n % 3 == 0 do
+  "Fizz"
+elif n % 5 == 0 do
+  "Buzz"
+else
+  "n"
+end
+end
+fizzbuzz(15) + "," + fizzbuzz(1) + "," + fizzbuzz(9) + "," + fizzbuzz(10)`},
+		{name: "else if chain", want: value.Str("B"), input: `let score = 85
+let grade = if score >= 90 do
+  "A"
+else if score >= 80 do
+  "B"
+else
+  "C"
+end
+grade`},
+		{name: "single line elif", want: value.Str("zero"), input: `let n = 0
+if n > 0 do "positive" elif n < 0 do "negative" else "zero"`},
+		{name: "return from block if", want: value.Int(1), input: `fn sign |n| ->
+if n > 0 do
+  return 1
+end
+0
+end
+sign(5)`},
+		{name: "break from block if in loop", want: value.Int(3), input: `let i = 0
+while true do
+  if i == 3 do
+    break
+  end
+  i = i + 1
+end
+i`},
+		{name: "match literals", want: value.Str("two"), input: `match 2 do
+  1 -> "one"
+  2 -> "two"
+  _ -> "many"
+end`},
+		{name: "match wildcard", want: value.Str("many"), input: `match 7 do
+  1 -> "one"
+  _ -> "many"
+end`},
+		{name: "match binds variable", want: value.Int(14), input: `match 7 do
+  n -> n * 2
+end`},
+		{name: "match guard", want: value.Str("negative"), input: `fn sign |n| ->
+match n do
+  0 -> "zero"
+  x if x < 0 -> "negative"
+  _ -> "positive"
+end
+end
+sign(-3)`},
+		{name: "match string and bool literals", want: value.Str("yes"), input: `let answer = match true do
+  false -> "no"
+  true -> "yes"
+end
+answer`},
+		{name: "match destructures list", want: value.Int(5), input: `match [2, 3] do
+  [] -> 0
+  [a] -> a
+  [a, b] -> a + b
+end`},
+		{name: "match destructures tuple", want: value.Str("positive x axis"), input: `let point = (4, 0)
+match point do
+  (0, 0) -> "origin"
+  (x, 0) if x > 0 -> "positive x axis"
+  _ -> "elsewhere"
+end`},
+		{name: "match destructures nested map", want: value.Str("ann is 4"), input: `let user = {"name": "ann", "age": 4, "tags": ["admin"]}
+match user do
+  {"name": name, "tags": []} -> name
+  {"name": name, "age": age} -> name + " is 4"
+end`},
+		{name: "match bindings do not leak", want: value.Int(1), input: `let n = 1
+match 5 do
+  n -> n
+end
+n`},
+		{name: "tuple indexing and length", want: value.Bool(true), input: `let pair = (1, "one")
+pair[1] == "one" and len(pair) == 2 and pair == (1, "one")`},
+		{name: "parentheses still group", want: value.Int(9), input: `(1 + 2) * (4 - 1)`},
+		{name: "string interpolation", want: value.Str("Hello ann, you are 5"), input: `let name = "ann"
+let age = 4
+"Hello #{name}, you are #{age + 1}"`},
+		{name: "interpolation formats values", want: value.Str(`[1, "a"] (1, 2) 2.5 true`), input: `let xs = [1, "a"]
+"#{xs} #{(1, 2)} #{5.0 / 2.0} #{1 < 2}"`},
+		{name: "interpolation calls functions", want: value.Str("3 items"), input: `fn count |xs| -> len(xs) end
+"#{count([1, 2, 3])} items"`},
+		{name: "interpolation sees local scope", want: value.Str("n=2"), input: `fn show |n| -> "n=#{n}" end
+show(2)`},
+		{name: "escapes in interpolated string", want: value.Str("name:\t\"ann\"\n"), input: `let name = "ann"
+"name:\t\"#{name}\"\n"`},
+		{name: "multi-line string with interpolation", want: value.Str("Dear ann,\n  #{not interpolated}\nbye"), input: `let name = "ann"
+let letter = """
+Dear #{name},
+  \#{not interpolated}
+bye"""
+letter`},
+		{name: "raw string", want: value.Str(`\d+\.\d+`), input: `r"\d+\.\d+"`},
+		{name: "bit mask literals", want: value.Bool(true), input: `let mask = 0b1111_0000
+mask == 0xF0 and mask == 0o360 and mask == 240`},
+		{name: "large constants", want: value.Bool(true), input: `let avogadro = 6.02e23
+let population = 8_000_000_000
+avogadro == 602_000_000_000.0e12 and population == 8 * 1_000_000_000`},
+		{name: "factorial promotes to big integer", want: value.Str("15511210043330985984000000"), input: `fn fact |n| -> if n < 2 do 1 else n * fact(n - 1) end
+"#{fact(25)}"`},
+		{name: "addition overflow promotes", want: value.Str("9223372036854775808"), input: `"#{9223372036854775807 + 1}"`},
+		{name: "subtraction overflow promotes", want: value.Str("-9223372036854775809"), input: `"#{-9223372036854775807 - 2}"`},
+		{name: "negating smallest int promotes", want: value.Str("9223372036854775808"), input: `let n = -9223372036854775807 - 1
+"#{0 - n}"`},
+		{name: "big results demote to int", want: value.Int(5), input: `let big = 99999999999999999999
+big - 99999999999999999994`},
+		{name: "big division and modulo", want: value.Bool(true), input: `let big = 100000000000000000000
+big / 7 == 14285714285714285714 and big % 7 == 2`},
+		{name: "big comparisons", want: value.Bool(true), input: `let big = 18446744073709551616
+big > 1 and 1 < big and big >= big and big == 18446744073709551616 and big != 1`},
+		{name: "checksum arithmetic", want: value.Str("340282366920938463463374607431768211455"), input: `let acc = 0
+for i in 0..<128 do acc = acc * 2 + 1 end
+"#{acc}"`},
+		{name: "big integers in collections", want: value.Bool(true), input: `let xs = [36893488147419103232]
+xs == [2 * 18446744073709551616] and 36893488147419103232 in xs`},
+		{name: "leading negative operand", want: value.Int(-7), input: `-5 - 2`},
+		{name: "int and float promote to float", want: value.Float(3.5), input: `1 + 2.5`},
+		{name: "mixed arithmetic", want: value.Float(2.5), input: `let n = 10
+n * 0.5 - 5 / 2.0`},
+		{name: "mixed comparison", want: value.Bool(true), input: `1 < 1.5 and 2.0 >= 2 and 1 == 1.0 and 3 != 3.5`},
+		{name: "mixed equality in collections", want: value.Bool(true), input: `[1, 2] == [1.0, 2] and 2.0 in [1, 2]`},
+		{name: "integer division truncates", want: value.Int(-3), input: `-7 / 2`},
+		{name: "floor division", want: value.Bool(true), input: `-7 // 2 == -4 and 7 // 2 == 3 and 7.5 // 2 == 3.0 and -7.5 // 2 == -4.0`},
+		{name: "modulo follows the divisor sign", want: value.Bool(true), input: `-7 % 3 == 2 and 7 % -3 == -2 and -6 % 3 == 0`},
+		{name: "float modulo", want: value.Bool(true), input: `-7.5 % 2 == 0.5 and 7.5 % 2 == 1.5 and 5 % 2.5 == 0.0`},
+		{name: "exponentiation", want: value.Bool(true), input: `2 ** 10 == 1024 and 2 ** -1 == 0.5 and 9 ** 0.5 == 3.0 and 2 ** 3 ** 2 == 512`},
+		{name: "exponentiation promotes to big integer", want: value.Str("1267650600228229401496703205376"), input: `"#{2 ** 100}"`},
+		{name: "unit powers with big exponents", want: value.Bool(true), input: `1 ** 100000000000000000000 == 1 and (-1) ** 100000000000000000001 == -1 and 0 ** 100000000000000000000 == 0`},
+		{name: "big integer floor division and modulo", want: value.Bool(true), input: `let big = 0 - 2 ** 64
+big // 10 == -1844674407370955162 and big % 10 == 4`},
+		{name: "big integer and float", want: value.Float(1.8446744073709552e19), input: `2 ** 64 + 0.0`},
+		{name: "int conversion", want: value.Bool(true), input: `int(3.99) == 3 and int(-3.99) == -3 and int("0xff") == 255 and int(" 1_000 ") == 1000 and int(true) == 1`},
+		{name: "int conversion of large float", want: value.Str("100000000000000000000"), input: `"#{int(1e20)}"`},
+		{name: "float conversion", want: value.Bool(true), input: `float(2) == 2.0 and float("2.5") == 2.5 and float(2 ** 64) == 18446744073709551616.0`},
+		{name: "round", want: value.Bool(true), input: `round(2.5) == 3 and round(-2.5) == -3 and round(2.4) == 2 and round(7) == 7`},
+		{name: "round to places", want: value.Bool(true), input: `round(3.14159, 2) == 3.14 and round(1250, -2) == 1300 and round(5, 2) == 5`},
+		{name: "decimals are exact", want: value.Bool(true), input: `0.1d + 0.2d == 0.3d and 0.1 + 0.2 != 0.3`},
+		{name: "unary operators on expressions", want: value.Bool(true), input: `let a = 2
+fn twice |x| -> x * 2 end
+-(a + 1) == -3 and -twice(a) == -4 and !(a > 3) and - -a == 2 and -2 ** 2 == -4 and -a * 3 == -6`},
+		{name: "calls and indexes inside operators", want: value.Bool(true), input: `fn inc |x| -> x + 1 end
+let xs = [1, 2, 3]
+inc(xs[0]) * inc(2) + {"k": 4}["k"] == 10 and (fn |x| -> x * x end)(3) == 9 and xs[inc(0)] ** 2 == 4`},
+		{name: "decimal keeps its scale", want: value.Str("12.30 -24.60 0.060"), input: `let price = 12.30d
+"#{price} #{-price * 2} #{0.2d * 0.30d}"`},
+		{name: "decimal arithmetic", want: value.Str("2.25 3 1.5 0.5 1.5"), input: `"#{1.5d ** 2} #{7.5d // 2} #{-7.5d % 3} #{-7.5d % 2} #{1 + 0.5d}"`},
+		{name: "decimal division uses the context", want: value.Str("3.3333333333333333333333333333 3.33 3.34 0.67"), input: `let exact = "#{10d / 3}"
+decimal_context(2)
+let rounded = "#{10d / 3}"
+decimal_context(2, "ceiling")
+let up = "#{10d / 3}"
+decimal_context(2, "half_even")
+"#{exact} #{rounded} #{up} #{2d / 3}"`},
+		{name: "decimal context is restorable", want: value.Str("0.3333"), input: `let previous = decimal_context(4, "down")
+let third = "#{1d / 3}"
+decimal_context(previous[0], previous[1])
+third`},
+		{name: "decimal context through a builtin value", want: value.Str("0.33"), input: `let set = decimal_context
+fn third || -> 1d / 3 end
+set(2)
+"#{third()}"`},
+		{name: "decimal rounding modes", want: value.Str("2.34 2.35 2.34 2 -2"), input: `let a = round(2.345d, 2)
+decimal_context(28, "half_up")
+let b = round(2.345d, 2)
+decimal_context(28, "half_down")
+let c = round(2.345d, 2)
+decimal_context(28, "half_even")
+"#{a} #{b} #{c} #{round(2.5d) + round(0.5d)} #{round(-2.5d)}"`},
+		{name: "billing total", want: value.Str("59.97 4.95 64.92"), input: `let items = [19.99d, 19.99d, 19.99d]
+let subtotal = 0d
+for item in items do subtotal = subtotal + item end
+let tax = round(subtotal * 0.0825d, 2)
+"#{subtotal} #{tax} #{subtotal + tax}"`},
+		{name: "unary operators on variables", want: value.Bool(true), input: `let n = 3
+let yes = true
+-n * 2 == -6 and !yes == false`},
+		{name: "decimal conversions", want: value.Bool(true), input: `decimal(0.1) == 0.1d and decimal("1_000.50") == 1000.5d and decimal(3) == 3d and int(-3.7d) == -3 and float(1.25d) == 1.25`},
+		{name: "decimal comparisons", want: value.Bool(true), input: `1d < 2 and 2.50d == 2.5d and 3 >= 2.99d and [1.0d] == [1] and 2d in [1, 2]`},
+		{name: "identifiers with digits, underscores and unicode", want: value.Int(12), input: `let x1 = 3
+let user_id = x1 * 2
+fn _double |n2| -> n2 * 2 end
+let größe = _double(user_id)
+größe`},
+		{name: "comments are ignored", want: value.Int(3), input: `# adds one
+fn inc |n| -> # the argument
+  n + 1 /* no /* nested */ side effects */
+end
+/*
+  inc(100)
+*/
+inc(2) # done`},
+		{name: "nested interpolation", want: value.Str("outer inner 1"), input: `let x = 1
+"outer #{"inner #{x}"}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := evalProgram(tt.input)
+			if err != nil || output != tt.want {
+				t.Errorf("got %#v, %v, want %#v", output, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalContextIsPerProgram(t *testing.T) {
+	if _, err := evalProgram(`decimal_context(2, "floor")`); err != nil {
+		t.Fatal(err)
+	}
+	got, err := evalProgram(`"#{2d / 3}"`)
+	if want := value.Str("0.6666666666666666666666666667"); err != nil || got != want {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "interpolation of undefined symbol", want: "error: undefined symbol 'nobody'", input: `"hi #{nobody}"`},
+		{name: "modulo by zero", want: "error: division by zero", input: `5 % 0`},
+		{name: "negated list", want: "error: unsupported type 'list' for -", input: `-[1]`},
+		{name: "length of the widest range", want: "error: len() of -9223372036854775807..9223372036854775807 does not fit in an int", input: `len(-9223372036854775807..9223372036854775807)`},
+		{name: "decimal string out of range", want: `error: decimal() of "1e2000000000" is out of range`, input: `decimal("1e2000000000")`},
+		{name: "decimal power out of range", want: "error: 10 ** 3000000000 is out of range", input: `10d ** 3000000000d`},
+		{name: "integer power out of range", want: "error: 2 ** 3000000000 is out of range", input: `2 ** 3000000000`},
+		{name: "big integer power out of range", want: "error: 100000000000000000000 ** 1000000 is out of range", input: `100000000000000000000 ** 1000000`},
+		{name: "integer power with a big exponent", want: "error: -3 ** 100000000000000000000 is out of range", input: `(-3) ** 100000000000000000000`},
+		{name: "decimal product out of range", want: "error: decimal product is out of range, it would have more than 100000 digits after the decimal point", input: `decimal("1e-100000") * decimal("1e-100000")`},
+		{name: "decimal context scale out of range", want: "error: decimal_context() scale 2000000000 is larger than 100000", input: `decimal_context(2000000000)`},
+		{name: "big integer range bound", want: "error: range bound 100000000000000000000 is out of range", input: `1..100000000000000000000`},
+		{name: "big integer index", want: "error: index 100000000000000000000 is out of range", input: `[1, 2][100000000000000000000]`},
+		{name: "big integer index assignment", want: "error: index -100000000000000000000 is out of range", input: `let xs = [1]
+xs[-100000000000000000000] = 2`},
+		{name: "big integer slice bound", want: "error: slice bound 100000000000000000000 is out of range", input: `"abc"[100000000000000000000:]`},
+		{name: "string range bound", want: "error: range bound must be an integer, got a(string)", input: `1.."a"`},
+		{name: "negated string literal", want: "error: unsupported type 'string' for -", input: `-"x"`},
+		{name: "negated string variable", want: "error: unsupported type 'string' for -", input: `let s = "ab"
+-s`},
+		{name: "not of an integer", want: "error: unsupported type 'int' for !", input: `!5`},
+		{name: "negated boolean", want: "error: unsupported type 'bool' for -", input: `-true`},
+		{name: "negated builtin", want: "error: unsupported type 'builtin' for -", input: `-len`},
+		{name: "not of a number", want: "error: unsupported type 'int' for !", input: `!(1 + 2)`},
+		{name: "float division by zero", want: "error: division by zero", input: `1 / 0.0`},
+		{name: "floor division by zero", want: "error: division by zero", input: `1 // 0`},
+		{name: "int of non numeric string", want: `error: int() cannot convert "abc" to an integer`, input: `int("abc")`},
+		{name: "int of infinity", want: "error: int() cannot convert +Inf to an integer", input: `int(float("inf"))`},
+		{name: "round with too many arguments", want: "error: arguments count mismatch. require: 1 or 2, got: 3", input: `round(1, 2, 3)`},
+		{name: "arithmetic on strings", want: "error: unsupported type 'string' for *", input: `"a" * 2`},
+		{name: "number and string", want: `error: mismatch types 1(int) and a(string)`, input: `1 - "a"`},
+		{name: "big division by zero", want: "error: division by zero", input: `18446744073709551616 / 0`},
+		{name: "no match clause", want: `error: no match clause matches "c"`, input: `match "c" do
+  "a" -> 1
+  "b" -> 2
+end`},
+		{name: "guard must be boolean", want: "error: condition must be a boolean, got 1(int)", input: `match 1 do
+  x if x -> x
+end`},
+		{name: "inline if local is out of scope", want: "error: undefined symbol 'x'", input: `if true do let x = 1
+x`},
+		{name: "expression as a pattern", want: "error: 1 + 2 cannot be used as a pattern", input: `match 3 do
+1 + 2 -> "sum"
+end`},
+		{name: "assignment to undefined symbol", want: "error: cannot assign to undefined symbol 'a', declare it first with 'let'", input: `a = 1`},
+		{name: "assignment to constant", want: "error: cannot assign to constant 'a'", input: `const a = 1
+a = 2`},
+		{name: "assignment to constant from inner scope", want: "error: cannot assign to constant 'limit'", input: `const limit = 1
+fn raise || -> limit = 2 end
+raise()`},
+		{name: "redeclaration of constant", want: "error: constant 'a' is already defined", input: `const a = 1
+let a = 2`},
+		{name: "unbounded recursion", want: "error: maximum call depth of 10000 exceeded", input: `fn loop |n| -> loop(n + 1) end
+loop(0)`},
+		{name: "break outside of loop", want: "error: 'break' outside of a loop", input: `fn f || -> break end
+f()`},
+		{name: "iterating over a number", want: "error: 10(int) is not iterable", input: `for i in 10 do i end`},
+		{name: "range with zero step", want: "error: range step cannot be zero", input: `for i in 1..3 by 0 do i end`},
+		{name: "list index out of range", want: "error: index 3 out of range for length 3", input: `[1, 2, 3][3]`},
+		{name: "list negative index out of range", want: "error: index -4 out of range for length 3", input: `[1, 2, 3][-4]`},
+		{name: "missing map key", want: `error: key "b" not found`, input: `{"a": 1}["b"]`},
+		{name: "unhashable map key", want: "error: [1](list) cannot be used as a map key", input: `{[1]: 2}`},
+		{name: "calling a non-function value", want: "error: 1(int) is not a function", input: `[1][0](2)`},
+		{name: "calling a non-function symbol", want: "error: 'a' is not a function", input: `let a = 1
+a()`},
+		{name: "non boolean condition", want: "error: condition must be a boolean, got 1(int)", input: `while 1 do 2 end`},
+		{name: "range and list", want: "error: mismatch types 1..3(range) and [1, 2, 3](list)", input: `1..3 == [1, 2, 3]`},
+		{name: "decimal and float do not mix", want: "error: mismatch types 1.5(decimal) and 1(float)", input: `1.5d + 1.0`},
+		{name: "decimal division by zero", want: "error: division by zero", input: `1d % 0`},
+		{name: "decimal fractional exponent", want: "error: decimal exponent must be an integer, got 0.5", input: `4d ** 0.5d`},
+		{name: "unknown rounding mode", want: `error: decimal_context() unknown rounding mode "sideways"`, input: `decimal_context(2, "sideways")`},
+		{name: "decimal of non-numeric string", want: `error: decimal() cannot convert "1.2.3" to a decimal`, input: `decimal("1.2.3")`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := evalProgram(tt.input)
+			if err == nil || "error: "+err.Message != tt.want {
+				t.Errorf("got %#v, %v, want %+v", output, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuntimeErrorLocations(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+		pos   string
+		end   int
+	}{
+		{name: "innermost expression", code: diagnostic.DivisionByZero, pos: "2:14", end: 27, input: `let n = 0
+let x = 1 + (2 / n)`},
+		{name: "undefined symbol", code: diagnostic.Undefined, pos: "1:5", end: 7, input: `1 + nope`},
+		{name: "inside a function body", code: diagnostic.TypeMismatch, pos: "2:3", end: 20, input: `fn f |a| ->
+  a + "x"
+end
+f(1)`},
+		{name: "number out of range", code: diagnostic.InvalidNumber, pos: "1:1", end: 14, input: `0.1d ** 200000d`},
+		{name: "statement in a loop", code: diagnostic.KeyNotFound, pos: "1:19", end: 22, input: `for k in ["a"] do {}[k] end`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := evalProgram(tt.input)
+			if err == nil {
+				t.Fatalf("got %#v, want an error", output)
+			}
+			if err.Code != tt.code || err.Pos.String() != tt.pos || err.End != tt.end {
+				t.Errorf("got %s at %s to %d, want %s at %s to %d", err.Code, err.Pos, err.End, tt.code, tt.pos, tt.end)
+			}
+		})
+	}
+}
+
+func TestRuntimeErrorFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "top level", want: "", input: `1 / 0`},
+		{name: "nested calls", want: "inner 3:3, outer 5:1", input: `fn inner |a| -> a / 0 end
+fn outer |a| ->
+  inner(a)
+end
+outer(1)`},
+		{name: "anonymous function", want: "<fn> 1:23, apply 2:1", input: `let apply = fn |f| -> f() end
+apply(fn || -> 1 / 0 end)`},
+		{name: "error in the arguments of a call", want: "", input: `fn f |a| -> a end
+f(1 / 0)`},
+		{name: "recursion", want: "count 1:41, count 1:41, count 2:1", input: `fn count |n| -> if n == 0 do 1 / n else count(n - 1) end
+count(2)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := evalProgram(tt.input)
+			if err == nil {
+				t.Fatalf("got %#v, want an error", output)
+			}
+			var got []string
+			for _, frame := range err.Frames {
+				got = append(got, frame.Function+" "+frame.Pos.String())
+			}
+			if strings.Join(got, ", ") != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
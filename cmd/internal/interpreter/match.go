@@ -0,0 +1,118 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
+	"github.com/iamBharatManral/atom.git/cmd/internal/env"
+	"github.com/iamBharatManral/atom.git/cmd/internal/error"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
+)
+
+func evalMatchExpression(stmt ast.MatchExpression, ev *env.Environment) (value.Value, value.Signal) {
+	subject, sig := evaluate(stmt.Subject, ev)
+	if sig != nil {
+		return nil, sig
+	}
+	for _, clause := range stmt.Clauses {
+		clauseEnv := env.NewEnclosed(ev)
+		matched, sig := matchPattern(clause.Pattern, subject, clauseEnv)
+		if sig != nil {
+			return nil, sig
+		}
+		if !matched {
+			continue
+		}
+		if clause.Guard != nil {
+			pass, sig := evalCondition(clause.Guard, clauseEnv)
+			if sig != nil {
+				return nil, sig
+			}
+			if !pass {
+				continue
+			}
+		}
+		return evaluate(clause.Body, clauseEnv)
+	}
+	return nil, error.NoMatchError(value.Inspect(subject))
+}
+
+// matchPattern reports whether v has the shape described by pattern,
+// binding the identifiers in the pattern to the matching parts of v in env.
+// '_' matches anything without binding it.
+func matchPattern(pattern ast.Expression, v value.Value, env *env.Environment) (bool, value.Signal) {
+	switch pattern := pattern.(type) {
+	case ast.Identifier:
+		switch pattern.Value {
+		case "_":
+			return true, nil
+		case "true", "false":
+			b, sig := evalIdentifier(pattern, env)
+			if sig != nil {
+				return false, sig
+			}
+			return b.Equal(v), nil
+		}
+		if pattern.UnaryOp != "" {
+			break
+		}
+		env.Set(pattern.Value, v)
+		return true, nil
+	case ast.Literal:
+		literal, err := evalLiteral(pattern)
+		if err != nil {
+			return false, err
+		}
+		return literal.Equal(v), nil
+	case ast.ListLiteral:
+		list, ok := v.(*value.List)
+		if !ok {
+			return false, nil
+		}
+		return matchElements(pattern.Elements, list.Elements, env)
+	case ast.TupleLiteral:
+		tuple, ok := v.(value.Tuple)
+		if !ok {
+			return false, nil
+		}
+		return matchElements(pattern.Elements, tuple.Elements, env)
+	case ast.MapLiteral:
+		m, ok := v.(*value.Map)
+		if !ok {
+			return false, nil
+		}
+		for i := range pattern.Keys {
+			key, sig := evaluate(pattern.Keys[i], env)
+			if sig != nil {
+				return false, sig
+			}
+			hashable, ok := key.(value.Hashable)
+			if !ok {
+				return false, error.UnhashableKeyError(key)
+			}
+			item, ok := m.Get(hashable)
+			if !ok {
+				return false, nil
+			}
+			matched, sig := matchPattern(pattern.Values[i], item, env)
+			if !matched || sig != nil {
+				return false, sig
+			}
+		}
+		return true, nil
+	}
+	return false, error.UnsupportedOperation(fmt.Sprintf("%s cannot be used as a pattern", pattern.String()))
+}
+
+func matchElements(patterns []ast.Expression, values []value.Value, env *env.Environment) (bool, value.Signal) {
+	if len(patterns) != len(values) {
+		return false, nil
+	}
+	for i, pattern := range patterns {
+		matched, sig := matchPattern(pattern, values[i], env)
+		if !matched || sig != nil {
+			return false, sig
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,199 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+	"github.com/iamBharatManral/atom.git/cmd/internal/env"
+	"github.com/iamBharatManral/atom.git/cmd/internal/error"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
+)
+
+// maxPowerBits bounds the size of an integer computed by **, so that
+// 2 ** 3000000000 is rejected instead of computed.
+const maxPowerBits = 1 << 22
+
+// evalArithmetic applies an arithmetic operator to two numbers of the same
+// kind, as value.Promote returns them. Inexact decimal results are rounded
+// with ctx.
+func evalArithmetic(operator string, left, right value.Value, ctx env.DecimalContext) (value.Value, value.Signal) {
+	switch l := left.(type) {
+	case value.Int:
+		return evalIntArithmetic(operator, int(l), int(right.(value.Int)))
+	case value.BigInt:
+		return evalBigArithmetic(operator, l.Int, right.(value.BigInt).Int)
+	case value.Decimal:
+		return evalDecimalArithmetic(operator, l.Decimal, right.(value.Decimal).Decimal, ctx)
+	}
+	return evalFloatArithmetic(operator, float64(left.(value.Float)), float64(right.(value.Float)))
+}
+
+func isDivision(operator string) bool {
+	return operator == "/" || operator == "//" || operator == "%"
+}
+
+func evalIntArithmetic(operator string, a, b int) (value.Value, value.Signal) {
+	if isDivision(operator) && b == 0 {
+		return nil, error.DivisonByZeroError()
+	}
+	switch operator {
+	case "+":
+		sum := a + b
+		if (a >= 0) == (b >= 0) && (sum >= 0) != (a >= 0) {
+			break
+		}
+		return value.Int(sum), nil
+	case "-":
+		difference := a - b
+		if (a >= 0) != (b >= 0) && (difference >= 0) != (a >= 0) {
+			break
+		}
+		return value.Int(difference), nil
+	case "*":
+		if a == 0 || b == 0 {
+			return value.Int(0), nil
+		}
+		product := a * b
+		if product/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+			break
+		}
+		return value.Int(product), nil
+	case "/", "//":
+		if a == math.MinInt && b == -1 {
+			break
+		}
+		quotient := a / b
+		if operator == "//" && a%b != 0 && (a < 0) != (b < 0) {
+			quotient--
+		}
+		return value.Int(quotient), nil
+	case "%":
+		remainder := a % b
+		if remainder != 0 && (remainder < 0) != (b < 0) {
+			remainder += b
+		}
+		return value.Int(remainder), nil
+	}
+	// the result overflows an int
+	return evalBigArithmetic(operator, big.NewInt(int64(a)), big.NewInt(int64(b)))
+}
+
+func evalBigArithmetic(operator string, a, b *big.Int) (value.Value, value.Signal) {
+	if isDivision(operator) && b.Sign() == 0 {
+		return nil, error.DivisonByZeroError()
+	}
+	n := new(big.Int)
+	switch operator {
+	case "+":
+		n.Add(a, b)
+	case "-":
+		n.Sub(a, b)
+	case "*":
+		n.Mul(a, b)
+	case "/":
+		n.Quo(a, b)
+	case "//":
+		remainder := new(big.Int)
+		n.QuoRem(a, b, remainder)
+		if remainder.Sign() != 0 && remainder.Sign() != b.Sign() {
+			n.Sub(n, big.NewInt(1))
+		}
+	case "%":
+		n.Rem(a, b)
+		if n.Sign() != 0 && n.Sign() != b.Sign() {
+			n.Add(n, b)
+		}
+	case "**":
+		if b.Sign() < 0 {
+			return value.Float(math.Pow(value.ToFloat(value.NormalizeInt(a)), value.ToFloat(value.NormalizeInt(b)))), nil
+		}
+		if a.CmpAbs(big.NewInt(1)) > 0 && (!b.IsInt64() || int64(a.BitLen()-1) > maxPowerBits/b.Int64()) {
+			return nil, error.NumberOutOfRangeError(fmt.Sprintf("%v ** %v is out of range", a, b))
+		}
+		n.Exp(a, b, nil)
+	}
+	return value.NormalizeInt(n), nil
+}
+
+func evalFloatArithmetic(operator string, a, b float64) (value.Value, value.Signal) {
+	if isDivision(operator) && b == 0 {
+		return nil, error.DivisonByZeroError()
+	}
+	switch operator {
+	case "+":
+		return value.Float(a + b), nil
+	case "-":
+		return value.Float(a - b), nil
+	case "*":
+		return value.Float(a * b), nil
+	case "/":
+		return value.Float(a / b), nil
+	case "//":
+		return value.Float(math.Floor(a / b)), nil
+	case "%":
+		remainder := math.Mod(a, b)
+		if remainder != 0 && (remainder < 0) != (b < 0) {
+			remainder += b
+		}
+		return value.Float(remainder), nil
+	}
+	return value.Float(math.Pow(a, b)), nil
+}
+
+// evalDecimalArithmetic is exact except for '/' and negative powers, which
+// are rounded to the scale of the decimal context.
+func evalDecimalArithmetic(operator string, a, b decimal.Decimal, ctx env.DecimalContext) (value.Value, value.Signal) {
+	if isDivision(operator) && b.Sign() == 0 {
+		return nil, error.DivisonByZeroError()
+	}
+	switch operator {
+	case "+":
+		return value.Decimal{Decimal: a.Add(b)}, nil
+	case "-":
+		return value.Decimal{Decimal: a.Sub(b)}, nil
+	case "*":
+		if a.Scale()+b.Scale() > decimal.MaxScale {
+			return nil, error.NumberOutOfRangeError(fmt.Sprintf("decimal product is out of range, it would have more than %d digits after the decimal point", decimal.MaxScale))
+		}
+		return value.Decimal{Decimal: a.Mul(b)}, nil
+	case "/":
+		q, _ := a.Quo(b, ctx.Scale, ctx.Rounding)
+		return value.Decimal{Decimal: q}, nil
+	case "//":
+		q, _ := a.Quo(b, 0, decimal.Floor)
+		return value.Decimal{Decimal: q}, nil
+	case "%":
+		remainder, _ := a.Mod(b)
+		return value.Decimal{Decimal: remainder}, nil
+	}
+	exponent, ok := value.NormalizeInt(b.Int()).(value.Int)
+	if !ok || b.Cmp(decimal.FromInt(b.Int())) != 0 {
+		return nil, error.UnsupportedOperation(fmt.Sprintf("decimal exponent must be an integer, got %v", b))
+	}
+	if exponent < 0 && a.Sign() == 0 {
+		return nil, error.DivisonByZeroError()
+	}
+	n := int(exponent)
+	if n < 0 {
+		n = -n
+	}
+	// negating the smallest int leaves it negative
+	power, err := a.Pow(n)
+	if n < 0 || err != nil {
+		return nil, error.NumberOutOfRangeError(fmt.Sprintf("%v ** %v is out of range", a, b))
+	}
+	if exponent >= 0 {
+		return value.Decimal{Decimal: power}, nil
+	}
+	q, _ := decimal.New(big.NewInt(1), 0).Quo(power, ctx.Scale, ctx.Rounding)
+	return value.Decimal{Decimal: q}, nil
+}
+
+func negateInt(a int) value.Value {
+	if a == math.MinInt {
+		return value.BigInt{Int: new(big.Int).Neg(big.NewInt(int64(a)))}
+	}
+	return value.Int(-a)
+}
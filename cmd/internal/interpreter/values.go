@@ -0,0 +1,88 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/error"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
+)
+
+// toInt converts an integer used as a range bound, an index or a slice
+// bound to an int. what names that use in the errors for integers too big
+// for an int and for values that are not integers.
+func toInt(v value.Value, what string) (int, *value.Error) {
+	switch n := v.(type) {
+	case value.Int:
+		return int(n), nil
+	case value.BigInt:
+		return 0, error.UnsupportedOperation(fmt.Sprintf("%s %v is out of range", what, n))
+	}
+	return 0, error.UnsupportedOperation(fmt.Sprintf("%s must be an integer, got %v(%s)", what, v, v.Kind()))
+}
+
+// normalizeIndex resolves a possibly negative index against a sequence of
+// the given length and reports whether it is in range.
+func normalizeIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
+
+// sliceBounds resolves possibly negative slice bounds against a sequence of
+// the given length, clamping them so that 0 <= low <= high <= length.
+func sliceBounds(low, high, length int) (int, int) {
+	clamp := func(bound int) int {
+		if bound < 0 {
+			bound += length
+		}
+		if bound < 0 {
+			return 0
+		}
+		if bound > length {
+			return length
+		}
+		return bound
+	}
+	low, high = clamp(low), clamp(high)
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+// iterate calls yield with every element of v until yield returns false.
+// It reports false when v cannot be iterated over.
+func iterate(v value.Value, yield func(value.Value) bool) bool {
+	switch v := v.(type) {
+	case value.Range:
+		v.Each(func(n int) bool { return yield(value.Int(n)) })
+	case value.Str:
+		for _, ch := range v {
+			if !yield(value.Str(ch)) {
+				break
+			}
+		}
+	case *value.List:
+		for _, element := range v.Elements {
+			if !yield(element) {
+				break
+			}
+		}
+	case value.Tuple:
+		for _, element := range v.Elements {
+			if !yield(element) {
+				break
+			}
+		}
+	case *value.Map:
+		for _, key := range v.Keys() {
+			if !yield(key) {
+				break
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
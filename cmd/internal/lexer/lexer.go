@@ -1,10 +1,16 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 	"strconv"
 	"unicode"
+	"unicode/utf8"
 
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/token"
 )
 
@@ -12,21 +18,76 @@ type Lexer struct {
 	input       []rune
 	currentPos  int
 	currentChar rune
-	line        uint
+	file        string
+	// lines holds the offset at which each line starts.
+	lines  []int
+	Errors []diagnostic.Diagnostic
+	// Comments holds the comments skipped so far, in source order.
+	Comments []token.Token
 }
 
 func New(input []rune) *Lexer {
+	return NewFile("", input)
+}
+
+// NewFile returns a lexer whose positions refer to the named file.
+func NewFile(file string, input []rune) *Lexer {
+	lines := []int{0}
+	for i, ch := range input {
+		if ch == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
 	return &Lexer{
 		input:       input,
 		currentPos:  -1,
 		currentChar: 0,
-		line:        1,
+		file:        file,
+		lines:       lines,
 	}
 }
+
+// Embedded returns a lexer for the part of the input between the offsets
+// start and end, such as an expression embedded in a string, whose
+// positions stay those of the whole input.
+func (l *Lexer) Embedded(start, end int) *Lexer {
+	// blanking out everything before start keeps the offsets, lines and
+	// columns of the part unchanged
+	input := make([]rune, end)
+	for i, ch := range l.input[:start] {
+		if ch != '\n' {
+			ch = ' '
+		}
+		input[i] = ch
+	}
+	copy(input[start:], l.input[start:end])
+	return NewFile(l.file, input)
+}
+
 func (l *Lexer) Line() uint {
-	return l.line
+	if l.currentPos < 0 {
+		return 1
+	}
+	return uint(l.Position(l.currentPos).Line)
 }
+
+// Position returns the position of the rune at offset.
+func (l *Lexer) Position(offset int) token.Position {
+	line := sort.Search(len(l.lines), func(i int) bool { return l.lines[i] > offset })
+	return token.Position{
+		File:   l.file,
+		Line:   line,
+		Column: offset - l.lines[line-1] + 1,
+		Offset: offset,
+	}
+}
+
 func (l *Lexer) NextToken() token.Token {
+	tok := l.nextToken()
+	return tok.WithPos(l.Position(tok.Start()))
+}
+
+func (l *Lexer) nextToken() token.Token {
 	if l.isAtEnd() {
 		return l.endOfFileToken()
 	}
@@ -45,9 +106,28 @@ func (l *Lexer) NextToken() token.Token {
 		}
 		return token.New(token.MINUS, "-", "", l.currentPos, l.currentPos)
 	case '*':
+		if l.peek() == '*' {
+			l.readChar()
+			return token.New(token.STARSTAR, "**", "", l.currentPos-1, l.currentPos)
+		}
 		return token.New(token.STAR, "*", "", l.currentPos, l.currentPos)
 	case '/':
+		if l.peek() == '*' {
+			if err := l.blockComment(); err != nil {
+				// an unclosed comment runs to the end of the input
+				l.Errors = append(l.Errors, err.(diagnostic.Diagnostic))
+				return l.endOfFileToken()
+			}
+			return l.nextToken()
+		}
+		if l.peek() == '/' {
+			l.readChar()
+			return token.New(token.SLASHSLASH, "//", "", l.currentPos-1, l.currentPos)
+		}
 		return token.New(token.SLASH, "/", "", l.currentPos, l.currentPos)
+	case '#':
+		l.lineComment()
+		return l.nextToken()
 	case '%':
 		return token.New(token.MOD, "%", "", l.currentPos, l.currentPos)
 
@@ -59,10 +139,10 @@ func (l *Lexer) NextToken() token.Token {
 		return token.New(token.ASSIGN, "=", "", l.currentPos, l.currentPos)
 	case '"':
 		{
-			tok, err := l.stringToken()
+			start := l.currentPos
+			tok, err := l.stringToken(start, false)
 			if err != nil {
-				fmt.Println(err.Error())
-				return l.endOfFileToken()
+				return l.errorToken(start, err)
 			}
 			return tok
 		}
@@ -78,6 +158,15 @@ func (l *Lexer) NextToken() token.Token {
 			return token.New(token.GE, ">=", "", l.currentPos-1, l.currentPos)
 		}
 		return token.New(token.GT, ">", "", l.currentPos, l.currentPos)
+	case '.':
+		if l.peek() == '.' {
+			l.readChar()
+			if l.peek() == '<' {
+				l.readChar()
+				return token.New(token.DOTDOTLT, "..<", "", l.currentPos-2, l.currentPos)
+			}
+			return token.New(token.DOTDOT, "..", "", l.currentPos-1, l.currentPos)
+		}
 	case '|':
 		return token.New(token.BAR, "|", "", l.currentPos, l.currentPos)
 	case '!':
@@ -92,35 +181,50 @@ func (l *Lexer) NextToken() token.Token {
 		return token.New(token.LPAREN, "(", "", l.currentPos, l.currentPos)
 	case ')':
 		return token.New(token.RPAREN, ")", "", l.currentPos, l.currentPos)
+	case '[':
+		return token.New(token.LBRACKET, "[", "", l.currentPos, l.currentPos)
+	case ']':
+		return token.New(token.RBRACKET, "]", "", l.currentPos, l.currentPos)
+	case '{':
+		return token.New(token.LBRACE, "{", "", l.currentPos, l.currentPos)
+	case '}':
+		return token.New(token.RBRACE, "}", "", l.currentPos, l.currentPos)
+	case ':':
+		return token.New(token.COLON, ":", "", l.currentPos, l.currentPos)
 
 	case '\n':
 		return token.New(token.NEWLINE, "", "", l.currentPos, l.currentPos)
 	default:
 		{
-			if unicode.IsDigit(l.currentChar) {
+			if isDigit(l.currentChar, 10) {
+				start := l.currentPos
 				tok, err := l.numberToken()
 				if err != nil {
-					fmt.Println(err.Error())
-					return l.endOfFileToken()
+					l.skipNumber()
+					return l.errorToken(start, err)
 				}
 				return tok
-			} else if unicode.IsLetter(l.currentChar) {
-				tok, err := l.identifier()
+			} else if l.currentChar == 'r' && l.peek() == '"' {
+				start := l.currentPos
+				l.readChar()
+				tok, err := l.stringToken(start, true)
 				if err != nil {
-					fmt.Println(err.Error())
-					return l.endOfFileToken()
+					return l.errorToken(start, err)
 				}
 				return tok
+			} else if isIdentifierStart(l.currentChar) {
+				return l.identifier()
 			}
 		}
 	}
-	return illegalToken(l.currentPos)
+	return l.illegalToken()
 }
 
 func (l *Lexer) PeekToken(peek int) token.Token {
 	oldPos := l.currentPos
 	oldChar := l.currentChar
-	oldLine := l.line
+	oldErrors := len(l.Errors)
+	oldComments := len(l.Comments)
 	var tt token.Token
 	for i := 1; i <= peek; i += 1 {
 		tt = l.NextToken()
@@ -130,25 +234,72 @@ func (l *Lexer) PeekToken(peek int) token.Token {
 	}
 	l.currentPos = oldPos
 	l.currentChar = oldChar
-	l.line = oldLine
+	l.Errors = l.Errors[:oldErrors]
+	l.Comments = l.Comments[:oldComments]
 	return tt
 }
 
-func (l *Lexer) identifier() (token.Token, error) {
-	// let
+func (l *Lexer) identifier() token.Token {
+	// let, x1, user_id, _tmp, größe
 	start := l.currentPos
-	for unicode.IsLetter(l.peek()) {
+	for isIdentifierChar(l.peek()) {
 		l.readChar()
 	}
-	if l.currentPos+1 == len(l.input) {
-		id := string(l.input[start : l.currentPos+1])
-		return token.New(token.IDENTIFIER, id, "", start, l.currentPos), nil
+	id := string(l.input[start : l.currentPos+1])
+	return token.New(token.IDENTIFIER, id, "", start, l.currentPos)
+}
+
+// isIdentifierStart reports whether ch can begin an identifier: a Unicode
+// letter or '_'.
+func isIdentifierStart(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
+}
+
+// isIdentifierChar reports whether ch can continue an identifier: a Unicode
+// letter, a decimal digit or '_'.
+func isIdentifierChar(ch rune) bool {
+	return isIdentifierStart(ch) || unicode.IsDigit(ch)
+}
+
+func (l *Lexer) lineComment() {
+	// # comment
+	start := l.currentPos
+	for l.peek() != '\n' && l.peek() != 0 {
+		l.readChar()
 	}
-	if unicode.IsDigit(l.peek()) {
-		return token.Token{}, fmt.Errorf("error: invalid identifer at line: %d, column %d", l.line, l.currentPos+1)
+	l.addComment(start, 1, 0)
+}
+
+// blockComment skips a block comment, which may contain other block
+// comments, whose '/' is the current character.
+func (l *Lexer) blockComment() error {
+	// /* comment /* nested */ */
+	start := l.currentPos
+	l.readChar()
+	depth := 1
+	for depth > 0 {
+		switch {
+		case l.peek() == 0:
+			return l.errorAt(diagnostic.UnclosedComment, start, start+1, "unclosed block comment")
+		case l.peek() == '/' && l.peekAt(2) == '*':
+			l.readChar()
+			depth++
+		case l.peek() == '*' && l.peekAt(2) == '/':
+			l.readChar()
+			depth--
+		}
+		l.readChar()
 	}
-	id := string(l.input[start : l.currentPos+1])
-	return token.New(token.IDENTIFIER, id, "", start, l.currentPos), nil
+	l.addComment(start, 2, 2)
+	return nil
+}
+
+// addComment records the comment ending at the current character, with the
+// given number of marker characters around its text.
+func (l *Lexer) addComment(start, open, close int) {
+	lexeme := string(l.input[start : l.currentPos+1])
+	text := string(l.input[start+open : l.currentPos+1-close])
+	l.Comments = append(l.Comments, token.New(token.COMMENT, lexeme, text, start, l.currentPos).WithPos(l.Position(start)))
 }
 
 func (l *Lexer) ignoreWhiteSpace() {
@@ -157,28 +308,180 @@ func (l *Lexer) ignoreWhiteSpace() {
 	}
 }
 
-func illegalToken(column int) token.Token {
-	return token.New("", "", token.ILLEGAL, column, column)
+func (l *Lexer) illegalToken() token.Token {
+	return token.New(token.ILLEGAL, string(l.currentChar), "", l.currentPos, l.currentPos)
+}
+
+// errorToken records err and returns the malformed token from start to the
+// current character as an INVALID token, so lexing resumes after it.
+func (l *Lexer) errorToken(start int, err error) token.Token {
+	l.Errors = append(l.Errors, err.(diagnostic.Diagnostic))
+	end := l.currentPos
+	if end >= len(l.input) {
+		end = len(l.input) - 1
+	}
+	return token.New(token.INVALID, string(l.input[start:end+1]), nil, start, end)
 }
 
 func (l *Lexer) endOfFileToken() token.Token {
 	return token.New(token.EOF, "", "", l.currentPos, l.currentPos)
 }
 
-func (l *Lexer) stringToken() (token.Token, error) {
-	start := l.currentPos
+// stringToken lexes a string literal whose opening quote is the current
+// character. A literal opened with three quotes may span several lines and
+// a raw one keeps backslashes and '#{' as they are.
+func (l *Lexer) stringToken(start int, raw bool) (token.Token, error) {
+	// "Hello #{name}!\n", """multi-line""", r"C:\path"
+	triple := l.peek() == '"' && l.peekAt(2) == '"'
+	if triple {
+		l.readChar()
+		l.readChar()
+		if l.peek() == '\n' {
+			l.readChar()
+		}
+	}
+	var parts []token.StringPart
+	var text []rune
+	textStart := l.currentPos + 1
+	flush := func() {
+		if len(text) > 0 {
+			parts = append(parts, token.StringPart{Text: string(text), Offset: textStart, End: l.currentPos})
+		}
+		text = nil
+	}
+	// a malformed escape sequence is reported once the rest of the literal
+	// has been read, so lexing resumes after its closing quote
+	var escapeErr error
+	for !l.isClosingQuote(triple) {
+		switch {
+		case escapeErr != nil && (l.peek() == 0 || l.peek() == '\n' && !triple):
+			return token.Token{}, escapeErr
+		case l.peek() == 0:
+			return token.Token{}, l.errorAt(diagnostic.UnclosedString, start, l.currentPos, "unclosed string")
+		case l.peek() == '\n' && !triple:
+			return token.Token{}, l.errorAt(diagnostic.UnclosedString, start, l.currentPos, "unclosed string").
+				WithNote(`use """ for multi-line strings`)
+		case l.peek() == '\\' && !raw:
+			l.readChar()
+			ch, err := l.escapeSequence()
+			if err != nil && escapeErr == nil {
+				escapeErr = err
+			}
+			text = append(text, ch)
+		case l.peek() == '#' && l.peekAt(2) == '{' && !raw:
+			flush()
+			l.readChar()
+			l.readChar()
+			exprStart := l.currentPos + 1
+			if err := l.skipInterpolation(); err != nil {
+				return token.Token{}, err
+			}
+			parts = append(parts, token.StringPart{Text: string(l.input[exprStart:l.currentPos]), Offset: exprStart, End: l.currentPos - 1, Expression: true})
+			textStart = l.currentPos + 1
+		default:
+			l.readChar()
+			text = append(text, l.currentChar)
+		}
+	}
+	if escapeErr != nil {
+		l.closeString(triple)
+		return token.Token{}, escapeErr
+	}
+	if parts == nil {
+		value := string(text)
+		l.closeString(triple)
+		return token.New(token.STRING, string(l.input[start:l.currentPos+1]), value, start, l.currentPos), nil
+	}
+	flush()
+	l.closeString(triple)
+	return token.New(token.INTERPOLATED, string(l.input[start:l.currentPos+1]), parts, start, l.currentPos), nil
+}
+
+func (l *Lexer) isClosingQuote(triple bool) bool {
+	if triple {
+		return l.peek() == '"' && l.peekAt(2) == '"' && l.peekAt(3) == '"'
+	}
+	return l.peek() == '"'
+}
+
+func (l *Lexer) closeString(triple bool) {
 	l.readChar()
-	if l.isAtEnd() {
-		return token.Token{}, fmt.Errorf("error: unclosed string at line: %d, column: %d", l.line, l.currentPos-1)
+	if triple {
+		l.readChar()
+		l.readChar()
 	}
-	for l.peek() != '"' && l.peek() != 0 {
+}
+
+// escapeSequence decodes the escape sequence whose backslash is the current
+// character.
+func (l *Lexer) escapeSequence() (rune, error) {
+	start := l.currentPos
+	l.readChar()
+	switch l.currentChar {
+	case 'n':
+		return '\n', nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case '0':
+		return 0, nil
+	case '\\', '"', '#':
+		return l.currentChar, nil
+	case 'u':
+		if l.peek() != '{' {
+			break
+		}
+		l.readChar()
+		digitsStart := l.currentPos + 1
+		for l.peek() != '}' && l.peek() != '"' && l.peek() != 0 {
+			l.readChar()
+		}
+		digits := string(l.input[digitsStart : l.currentPos+1])
+		if l.peek() != '}' {
+			return 0, l.errorAt(diagnostic.InvalidEscape, start, l.currentPos, "unclosed unicode escape '\\u{%s'", digits)
+		}
 		l.readChar()
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+			return 0, l.errorAt(diagnostic.InvalidEscape, start, l.currentPos, "invalid unicode escape '\\u{%s}'", digits).
+				WithNote("a unicode escape holds 1 to 6 hexadecimal digits of a code point up to 10FFFF")
+		}
+		return rune(code), nil
+	case 0:
+		return 0, l.errorAt(diagnostic.UnclosedString, start, start, "unclosed string")
 	}
-	if l.peek() == '"' {
+	return 0, l.errorAt(diagnostic.InvalidEscape, start, l.currentPos, "invalid escape sequence '\\%c'", l.currentChar).
+		WithNote(`the escape sequences are \n \t \r \0 \\ \" \# and \u{...}`)
+}
+
+// skipInterpolation advances to the '}' closing an interpolation whose '#{'
+// has just been read, skipping over nested braces and string literals.
+func (l *Lexer) skipInterpolation() error {
+	start := l.currentPos - 1
+	depth := 1
+	for {
+		switch l.peek() {
+		case 0:
+			return l.errorAt(diagnostic.UnclosedInterpolation, start, start+1, "unclosed interpolation")
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			l.readChar()
+			for l.peek() != '"' && l.peek() != 0 {
+				if l.peek() == '\\' {
+					l.readChar()
+				}
+				l.readChar()
+			}
+		}
 		l.readChar()
-		return token.New(token.STRING, string(l.input[start:l.currentPos+1]), string(l.input[start+1:l.currentPos]), start, l.currentPos), nil
+		if depth == 0 {
+			return nil
+		}
 	}
-	return token.Token{}, fmt.Errorf("error: unclosed string at line: %d, column: %d", l.line, l.currentPos)
 }
 
 func (l *Lexer) isAtEnd() bool {
@@ -204,24 +507,166 @@ func (l *Lexer) peek() rune {
 	return l.input[l.currentPos+1]
 }
 
+// peekAt returns the rune n positions ahead of the current one, or 0 past
+// the end of the input.
+func (l *Lexer) peekAt(n int) rune {
+	if l.currentPos+n >= len(l.input) {
+		return 0
+	}
+	return l.input[l.currentPos+n]
+}
+
 func (l *Lexer) numberToken() (token.Token, error) {
+	// 42, 1_000_000, 0xFF, 0o17, 0b1010, 3.14, 6.02e23, 12.30d
 	start := l.currentPos
-	beforeDecPoint, err := l.readNumber()
+	if base := basePrefix(l.currentChar, l.peek()); base != 0 {
+		l.readChar()
+		digits, err := l.readDigits(base)
+		if err != nil {
+			return token.Token{}, err
+		}
+		if err := l.checkNumberEnd(base); err != nil {
+			return token.Token{}, err
+		}
+		if digits == "" {
+			return token.Token{}, l.numberError(fmt.Sprintf("missing digits after '%s'", string(l.input[start:l.currentPos+1])))
+		}
+		return l.integerToken(digits, base, start)
+	}
+	digits, err := l.readDigits(10, l.currentChar)
 	if err != nil {
 		return token.Token{}, err
 	}
-	if l.peek() == '.' {
+	float := false
+	if l.peek() == '.' && l.peekAt(2) != '.' {
+		l.readChar()
+		fraction, err := l.readDigits(10)
+		if err != nil {
+			return token.Token{}, err
+		}
+		if fraction == "" {
+			return token.Token{}, l.numberError("missing digits after decimal point")
+		}
+		digits += "." + fraction
+		float = true
+	}
+	if ch := l.peek(); ch == 'e' || ch == 'E' {
 		l.readChar()
-		afterDecPoint, err := l.readNumber()
+		exponent := string(ch)
+		if sign := l.peek(); sign == '+' || sign == '-' {
+			l.readChar()
+			exponent += string(sign)
+		}
+		expDigits, err := l.readDigits(10)
 		if err != nil {
 			return token.Token{}, err
 		}
-		decimal := append(beforeDecPoint, afterDecPoint...)
-		number, _ := strconv.ParseFloat(string(decimal), 64)
-		return token.New(token.FLOAT, "", number, start, l.currentPos), nil
+		if expDigits == "" {
+			return token.Token{}, l.numberError("missing digits in exponent")
+		}
+		digits += exponent + expDigits
+		float = true
+	}
+	if l.peek() == 'd' && !isIdentifierChar(l.peekAt(2)) {
+		l.readChar()
+		number, err := decimal.Parse(digits)
+		if errors.Is(err, decimal.ErrRange) {
+			return token.Token{}, l.numberErrorAt(start, fmt.Sprintf("decimal literal %s is out of range", string(l.input[start:l.currentPos+1])))
+		}
+		if err != nil {
+			return token.Token{}, l.numberErrorAt(start, fmt.Sprintf("invalid decimal literal %s", string(l.input[start:l.currentPos+1])))
+		}
+		return token.New(token.DECIMAL, "", number, start, l.currentPos), nil
+	}
+	if err := l.checkNumberEnd(10); err != nil {
+		return token.Token{}, err
+	}
+	if !float {
+		return l.integerToken(digits, 10, start)
+	}
+	number, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return token.Token{}, l.numberErrorAt(start, fmt.Sprintf("float literal %s is out of range", string(l.input[start:l.currentPos+1])))
+	}
+	return token.New(token.FLOAT, "", number, start, l.currentPos), nil
+}
+
+func (l *Lexer) integerToken(digits string, base int, start int) (token.Token, error) {
+	// literals too big for an int become big integers
+	number, err := strconv.ParseInt(digits, base, strconv.IntSize)
+	if err != nil {
+		n, ok := new(big.Int).SetString(digits, base)
+		if !ok {
+			return token.Token{}, l.numberErrorAt(start, fmt.Sprintf("invalid integer literal %s", string(l.input[start:l.currentPos+1])))
+		}
+		return token.New(token.INTEGER, "", n, start, l.currentPos), nil
+	}
+	return token.New(token.INTEGER, "", int(number), start, l.currentPos), nil
+}
+
+// basePrefix returns the base announced by a '0x', '0o' or '0b' prefix, or 0
+// when there is none.
+func basePrefix(ch, next rune) int {
+	if ch != '0' {
+		return 0
+	}
+	switch unicode.ToLower(next) {
+	case 'x':
+		return 16
+	case 'o':
+		return 8
+	case 'b':
+		return 2
+	}
+	return 0
+}
+
+// readDigits reads the digits in the given base that follow the current
+// character and returns them, after any digits already read, without the
+// '_' separators between them.
+func (l *Lexer) readDigits(base int, digits ...rune) (string, error) {
+	for {
+		ch := l.peek()
+		if ch == '_' {
+			if len(digits) == 0 || !isDigit(l.peekAt(2), base) {
+				l.readChar()
+				return "", l.numberError("'_' must separate digits")
+			}
+			l.readChar()
+			continue
+		}
+		if !isDigit(ch, base) {
+			return string(digits), nil
+		}
+		l.readChar()
+		digits = append(digits, ch)
+	}
+}
+
+// checkNumberEnd reports an error if a number literal in the given base is
+// directly followed by a character that could continue it.
+func (l *Lexer) checkNumberEnd(base int) error {
+	ch := l.peek()
+	if !isIdentifierChar(ch) {
+		return nil
+	}
+	l.readChar()
+	switch base {
+	case 16:
+		return l.numberError(fmt.Sprintf("invalid digit '%c' in hexadecimal literal", ch))
+	case 8:
+		return l.numberError(fmt.Sprintf("invalid digit '%c' in octal literal", ch))
+	case 2:
+		return l.numberError(fmt.Sprintf("invalid digit '%c' in binary literal", ch))
+	}
+	return l.numberError(fmt.Sprintf("invalid character '%c' in number literal", ch))
+}
+
+// skipNumber moves past the rest of a malformed number literal.
+func (l *Lexer) skipNumber() {
+	for isIdentifierChar(l.peek()) || l.peek() == '.' && isDigit(l.peekAt(2), 10) {
+		l.readChar()
 	}
-	number, _ := strconv.Atoi(string(beforeDecPoint))
-	return token.New(token.INTEGER, "", number, start, l.currentPos), nil
 }
 
 func (l *Lexer) isWhiteSpace() bool {
@@ -229,16 +674,33 @@ func (l *Lexer) isWhiteSpace() bool {
 	return ch == '\t' || ch == '\r' || ch == ' '
 }
 
-func (l *Lexer) readNumber() ([]rune, error) {
-	start := l.currentPos
-	for unicode.IsDigit(l.peek()) {
-		l.readChar()
+func isDigit(ch rune, base int) bool {
+	switch base {
+	case 16:
+		return unicode.Is(unicode.ASCII_Hex_Digit, ch)
+	case 8:
+		return ch >= '0' && ch <= '7'
+	case 2:
+		return ch == '0' || ch == '1'
 	}
-	nextChar := l.peek()
-	if nextChar == '.' || unicode.IsSpace(nextChar) || !unicode.IsLetter(nextChar) {
-		return l.input[start : l.currentPos+1], nil
-	}
-	return nil, fmt.Errorf("error: illegal number at line: %d, columns: %d", l.line, l.currentPos+1)
+	return ch >= '0' && ch <= '9'
+}
+
+// numberError reports a malformed number literal at the current character.
+func (l *Lexer) numberError(msg string) error {
+	return l.numberErrorAt(l.currentPos, msg)
+}
+
+// numberErrorAt reports a malformed number literal from the offset start to
+// the current character.
+func (l *Lexer) numberErrorAt(start int, msg string) error {
+	return l.errorAt(diagnostic.InvalidNumber, start, l.currentPos, "%s", msg)
+}
+
+// errorAt returns a diagnostic for the runes from the offset start to the
+// offset end.
+func (l *Lexer) errorAt(code string, start, end int, format string, args ...any) diagnostic.Diagnostic {
+	return diagnostic.New(code, l.Position(start), end, format, args...)
 }
 
 func (l *Lexer) Len() uint {
@@ -1,9 +1,12 @@
 package lexer
 
 import (
+	"math/big"
 	"reflect"
 	"testing"
 
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/token"
 )
 
@@ -25,6 +28,10 @@ func TestTokens(t *testing.T) {
 			token.New(token.STRING, "\"hello\"", "hello", 0, 6),
 			token.New(token.EOF, "", "", 7, 7),
 		}, input: "\"hello\""},
+		{name: "empty string", want: []token.Token{
+			token.New(token.STRING, "\"\"", "", 0, 1),
+			token.New(token.EOF, "", "", 2, 2),
+		}, input: "\"\""},
 		{name: "boolean true", want: []token.Token{
 			token.New(token.IDENTIFIER, "true", "", 0, 3),
 			token.New(token.EOF, "", "", 4, 4),
@@ -174,6 +181,134 @@ func TestTokens(t *testing.T) {
 			token.New(token.RPAREN, ")", "", 10, 10),
 			token.New(token.EOF, "", "", 11, 11),
 		}, input: `hello(a, b)`},
+		{name: "inclusive range", want: []token.Token{
+			token.New(token.INTEGER, "", 1, 0, 0),
+			token.New(token.DOTDOT, "..", "", 1, 2),
+			token.New(token.INTEGER, "", 10, 3, 4),
+			token.New(token.EOF, "", "", 5, 5),
+		}, input: `1..10`},
+		{name: "exclusive range", want: []token.Token{
+			token.New(token.INTEGER, "", 0, 0, 0),
+			token.New(token.DOTDOTLT, "..<", "", 1, 3),
+			token.New(token.IDENTIFIER, "n", "", 4, 4),
+			token.New(token.EOF, "", "", 5, 5),
+		}, input: `0..<n`},
+		{name: "list slicing", want: []token.Token{
+			token.New(token.IDENTIFIER, "xs", "", 0, 1),
+			token.New(token.LBRACKET, "[", "", 2, 2),
+			token.New(token.INTEGER, "", 1, 3, 3),
+			token.New(token.COLON, ":", "", 4, 4),
+			token.New(token.RBRACKET, "]", "", 5, 5),
+			token.New(token.EOF, "", "", 6, 6),
+		}, input: `xs[1:]`},
+		{name: "interpolated string", want: []token.Token{
+			token.New(token.INTERPOLATED, `"hi #{name}!"`, []token.StringPart{
+				{Text: "hi ", Offset: 1, End: 3},
+				{Text: "name", Offset: 6, End: 9, Expression: true},
+				{Text: "!", Offset: 11, End: 11},
+			}, 0, 12),
+			token.New(token.EOF, "", "", 13, 13),
+		}, input: `"hi #{name}!"`},
+		{name: "interpolation with nested braces and strings", want: []token.Token{
+			token.New(token.INTERPOLATED, `"#{{"}": 1}["}"]}"`, []token.StringPart{
+				{Text: `{"}": 1}["}"]`, Offset: 3, End: 15, Expression: true},
+			}, 0, 17),
+			token.New(token.EOF, "", "", 18, 18),
+		}, input: `"#{{"}": 1}["}"]}"`},
+		{name: "escape sequences", want: []token.Token{
+			token.New(token.STRING, `"a\tb\n\"c\" \\ \#{x}"`, "a\tb\n\"c\" \\ #{x}", 0, 21),
+			token.New(token.EOF, "", "", 22, 22),
+		}, input: `"a\tb\n\"c\" \\ \#{x}"`},
+		{name: "unicode escape", want: []token.Token{
+			token.New(token.STRING, `"\u{1F680}!"`, "🚀!", 0, 11),
+			token.New(token.EOF, "", "", 12, 12),
+		}, input: `"\u{1F680}!"`},
+		{name: "raw string", want: []token.Token{
+			token.New(token.STRING, `r"C:\new\#{x}"`, `C:\new\#{x}`, 0, 13),
+			token.New(token.EOF, "", "", 14, 14),
+		}, input: `r"C:\new\#{x}"`},
+		{name: "triple quoted string", want: []token.Token{
+			token.New(token.STRING, "\"\"\"\nsay \"hi\"\n  there\"\"\"", "say \"hi\"\n  there", 0, 22),
+			token.New(token.NEWLINE, "", "", 23, 23),
+			token.New(token.IDENTIFIER, "x", "", 24, 24),
+			token.New(token.EOF, "", "", 25, 25),
+		}, input: "\"\"\"\nsay \"hi\"\n  there\"\"\"\nx"},
+		{name: "identifier starting with r", want: []token.Token{
+			token.New(token.IDENTIFIER, "rest", "", 0, 3),
+			token.New(token.EOF, "", "", 4, 4),
+		}, input: `rest`},
+		{name: "identifiers with digits, underscores and unicode", want: []token.Token{
+			token.New(token.IDENTIFIER, "x1", "", 0, 1),
+			token.New(token.IDENTIFIER, "user_id", "", 3, 9),
+			token.New(token.IDENTIFIER, "_tmp2", "", 11, 15),
+			token.New(token.IDENTIFIER, "größe", "", 17, 21),
+			token.New(token.IDENTIFIER, "π", "", 23, 23),
+			token.New(token.EOF, "", "", 24, 24),
+		}, input: `x1 user_id _tmp2 größe π`},
+		{name: "non-ASCII digits are not numbers", want: []token.Token{
+			token.New(token.ILLEGAL, "٣", "", 0, 0),
+			token.New(token.IDENTIFIER, "x٣", "", 2, 3),
+			token.New(token.EOF, "", "", 4, 4),
+		}, input: `٣ x٣`},
+		{name: "digits cannot start an identifier", want: []token.Token{
+			token.New(token.INTEGER, "", 2, 0, 0),
+			token.New(token.PLUS, "+", "", 2, 2),
+			token.New(token.IDENTIFIER, "x2y", "", 4, 6),
+			token.New(token.EOF, "", "", 7, 7),
+		}, input: `2 + x2y`},
+		{name: "hexadecimal, octal and binary integers", want: []token.Token{
+			token.New(token.INTEGER, "", 255, 0, 3),
+			token.New(token.INTEGER, "", 15, 5, 8),
+			token.New(token.INTEGER, "", 10, 10, 15),
+			token.New(token.INTEGER, "", 0xdead_beef, 17, 27),
+			token.New(token.EOF, "", "", 28, 28),
+		}, input: `0xFF 0o17 0b1010 0xdead_BEEF`},
+		{name: "big integers", want: []token.Token{
+			token.New(token.INTEGER, "", bigInt("123456789012345678901234567890"), 0, 29),
+			token.New(token.INTEGER, "", bigInt("295147905179352825855"), 31, 49),
+			token.New(token.EOF, "", "", 50, 50),
+		}, input: `123456789012345678901234567890 0xFFFFFFFFFFFFFFFFF`},
+		{name: "digit separators", want: []token.Token{
+			token.New(token.INTEGER, "", 1000000, 0, 8),
+			token.New(token.FLOAT, "", 1234.5678, 10, 20),
+			token.New(token.EOF, "", "", 21, 21),
+		}, input: `1_000_000 1_234.56_78`},
+		{name: "scientific notation", want: []token.Token{
+			token.New(token.FLOAT, "", 6.02e23, 0, 6),
+			token.New(token.FLOAT, "", 1e-9, 8, 11),
+			token.New(token.FLOAT, "", 2.5e+3, 13, 18),
+			token.New(token.EOF, "", "", 19, 19),
+		}, input: `6.02e23 1e-9 2.5E+3`},
+		{name: "decimals", want: []token.Token{
+			token.New(token.DECIMAL, "", dec("12.30"), 0, 5),
+			token.New(token.DECIMAL, "", dec("1000"), 7, 12),
+			token.New(token.DECIMAL, "", dec("1.5e-3"), 14, 20),
+			token.New(token.EOF, "", "", 21, 21),
+		}, input: `12.30d 1_000d 1.5e-3d`},
+		{name: "floor division and exponentiation", want: []token.Token{
+			token.New(token.INTEGER, "", 7, 0, 0),
+			token.New(token.SLASHSLASH, "//", "", 2, 3),
+			token.New(token.INTEGER, "", 2, 5, 5),
+			token.New(token.STARSTAR, "**", "", 6, 7),
+			token.New(token.INTEGER, "", 3, 8, 8),
+			token.New(token.EOF, "", "", 9, 9),
+		}, input: `7 // 2**3`},
+		{name: "line comment", want: []token.Token{
+			token.New(token.INTEGER, "", 1, 0, 0),
+			token.New(token.NEWLINE, "", "", 9, 9),
+			token.New(token.INTEGER, "", 2, 10, 10),
+			token.New(token.EOF, "", "", 11, 11),
+		}, input: "1 # one\t#\n2"},
+		{name: "block comment", want: []token.Token{
+			token.New(token.INTEGER, "", 1, 0, 0),
+			token.New(token.SLASH, "/", "", 19, 19),
+			token.New(token.INTEGER, "", 2, 21, 21),
+			token.New(token.EOF, "", "", 22, 22),
+		}, input: "1 /* a /* b */\n */ / 2"},
+		{name: "hash without brace is plain text", want: []token.Token{
+			token.New(token.STRING, `"#1"`, "#1", 0, 3),
+			token.New(token.EOF, "", "", 4, 4),
+		}, input: `"#1"`},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -185,8 +320,157 @@ func TestTokens(t *testing.T) {
 				tok = lexer.NextToken()
 			}
 			ans = append(ans, tok)
-			if !reflect.DeepEqual(ans, tt.want) {
-				t.Errorf("got %+v, want %+v", ans, tt.want)
+			if want := located(tt.input, tt.want); !reflect.DeepEqual(ans, want) {
+				t.Errorf("got %+v, want %+v", ans, want)
+			}
+		})
+	}
+}
+
+func bigInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+
+// located returns tokens with the positions of their starts in input.
+func located(input string, tokens []token.Token) []token.Token {
+	var located []token.Token
+	for _, tok := range tokens {
+		located = append(located, tok.WithPos(position(input, tok.Start())))
+	}
+	return located
+}
+
+// position locates offset in input by counting the lines before it.
+func position(input string, offset int) token.Position {
+	runes := []rune(input)
+	line, lineStart := 1, 0
+	for i := 0; i < offset && i < len(runes); i++ {
+		if runes[i] == '\n' {
+			line, lineStart = line+1, i+1
+		}
+	}
+	return token.Position{Line: line, Column: offset - lineStart + 1, Offset: offset}
+}
+
+func dec(s string) decimal.Decimal {
+	d, _ := decimal.Parse(s)
+	return d
+}
+
+func messages(errors []diagnostic.Diagnostic) []string {
+	var messages []string
+	for _, err := range errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "invalid escape", want: []string{`error: invalid escape sequence '\q' at line: 1, column: 4`}, input: `"ab\q"`},
+		{name: "invalid unicode escape", want: []string{`error: invalid unicode escape '\u{110000}' at line: 2, column: 2`}, input: "1\n\"\\u{110000}\""},
+		{name: "unicode escape without digits", want: []string{`error: invalid unicode escape '\u{}' at line: 1, column: 2`}, input: `"\u{}"`},
+		{name: "unclosed unicode escape", want: []string{`error: unclosed unicode escape '\u{1F' at line: 1, column: 2`}, input: `"\u{1F"`},
+		{name: "newline in string", want: []string{`error: unclosed string at line: 1, column: 1`, `error: unclosed string at line: 2, column: 3`}, input: "\"ab\ncd\""},
+		{name: "unclosed triple quoted string", want: []string{`error: unclosed string at line: 3, column: 3`}, input: "1\n\n  \"\"\"ab\ncd"},
+		{name: "error after multi-line string", want: []string{`error: invalid escape sequence '\z' at line: 4, column: 2`}, input: "\"\"\"\na\nb\"\"\"\n\"\\z\""},
+		{name: "invalid hex digit", want: []string{`error: invalid digit 'G' in hexadecimal literal at line: 1, column: 8`}, input: `x = 0xFG`},
+		{name: "invalid binary digit", want: []string{`error: invalid digit '2' in binary literal at line: 1, column: 5`}, input: `0b102`},
+		{name: "invalid octal digit", want: []string{`error: invalid digit '8' in octal literal at line: 1, column: 3`}, input: `0o8`},
+		{name: "missing digits after prefix", want: []string{`error: missing digits after '0x' at line: 1, column: 2`}, input: `0x`},
+		{name: "trailing separator", want: []string{`error: '_' must separate digits at line: 2, column: 4`}, input: "1\n100_"},
+		{name: "double separator", want: []string{`error: '_' must separate digits at line: 1, column: 2`}, input: `1__0`},
+		{name: "separator after prefix", want: []string{`error: '_' must separate digits at line: 1, column: 3`}, input: `0x_FF`},
+		{name: "missing fraction", want: []string{`error: missing digits after decimal point at line: 1, column: 2`}, input: `1.`},
+		{name: "missing exponent", want: []string{`error: missing digits in exponent at line: 1, column: 3`}, input: `2e+`},
+		{name: "letter after number", want: []string{`error: invalid character 'a' in number literal at line: 1, column: 3`}, input: `12a`},
+		{name: "letter after decimal suffix", want: []string{`error: invalid character 'd' in number literal at line: 1, column: 4`}, input: `1.5dx`},
+		{name: "lexing resumes after a malformed string", want: []string{
+			`error: invalid escape sequence '\q' at line: 1, column: 3`,
+			`error: invalid digit 'G' in hexadecimal literal at line: 1, column: 14`,
+		}, input: `"a\q\z" + 0xFG1 + "ok"`},
+		{name: "lexing resumes on the next line", want: []string{
+			`error: unclosed string at line: 1, column: 5`,
+			`error: '_' must separate digits at line: 2, column: 6`,
+		}, input: "x = \"abc\ny = 1__000.5"},
+		{name: "decimal out of range", want: []string{`error: decimal literal 1e2000000000d is out of range at line: 1, column: 1`}, input: `1e2000000000d`},
+		{name: "float out of range", want: []string{`error: float literal 1e400 is out of range at line: 1, column: 1`}, input: `1e400`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := New([]rune(tt.input))
+			for lexer.NextToken().TokenType() != token.EOF {
+			}
+			if got := messages(lexer.Errors); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []token.Token
+		errors []string
+	}{
+		{name: "line comments", want: []token.Token{
+			token.New(token.COMMENT, "# first", " first", 0, 6),
+			token.New(token.COMMENT, "#second", "second", 13, 19),
+		}, input: "# first\nx = 1#second"},
+		{name: "nested block comment", want: []token.Token{
+			token.New(token.COMMENT, "/* a /* b */ c */", " a /* b */ c ", 2, 18),
+		}, input: "x /* a /* b */ c */"},
+		{name: "comment markers inside strings", want: nil, input: `"# /* not a comment"`},
+		{name: "unclosed block comment", want: nil, errors: []string{
+			"error: unclosed block comment at line: 2, column: 3",
+		}, input: "1\n  /* a /* b */"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := New([]rune(tt.input))
+			for lexer.NextToken().TokenType() != token.EOF {
+			}
+			if want := located(tt.input, tt.want); !reflect.DeepEqual(lexer.Comments, want) {
+				t.Errorf("got %+v, want %+v", lexer.Comments, want)
+			}
+			if got := messages(lexer.Errors); !reflect.DeepEqual(got, tt.errors) {
+				t.Errorf("got errors %q, want %q", got, tt.errors)
+			}
+		})
+	}
+}
+
+func TestPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		file  string
+		want  []string
+	}{
+		{name: "lines and columns", want: []string{"1:1", "1:5", "1:6", "2:1", "3:3", "3:4", "3:5"}, input: "let x\n\n  f()"},
+		{name: "crlf line endings", want: []string{"1:1", "1:3", "2:1", "2:3", "3:1"}, input: "a\r\nb\r\nc"},
+		{name: "tabs count as one column", want: []string{"1:2", "1:3", "2:3", "2:5"}, input: "\tx\n\t\ty = "},
+		{name: "multi-byte runes", want: []string{"1:1", "1:7", "1:9", "1:12", "2:3"}, input: "größe = \"😀\"\n  π"},
+		{name: "multi-line strings", want: []string{"1:1", "3:6"}, input: "\"\"\"\na\nb\"\"\" x"},
+		{name: "comments", want: []string{"1:1", "1:8", "2:11", "2:12", "3:1"}, input: "a # one\n/* two */ b\nc"},
+		{name: "file name", file: "main.om", want: []string{"main.om:1:1", "main.om:1:2", "main.om:2:3"}, input: "1\n  2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexer := NewFile(tt.file, []rune(tt.input))
+			var got []string
+			for tok := lexer.NextToken(); tok.TokenType() != token.EOF; tok = lexer.NextToken() {
+				got = append(got, tok.Pos().String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
 			}
 		})
 	}
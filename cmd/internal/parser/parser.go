@@ -1,20 +1,20 @@
 package parser
 
 import (
-	"fmt"
-	"reflect"
 	"slices"
 
 	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/lexer"
 	"github.com/iamBharatManral/atom.git/cmd/internal/token"
 )
 
 type Parser struct {
-	lexer        *lexer.Lexer
-	currentToken token.Token
-	peekToken    token.Token
-	Errors       []string
+	lexer         *lexer.Lexer
+	previousToken token.Token
+	currentToken  token.Token
+	peekToken     token.Token
+	Errors        []diagnostic.Diagnostic
 }
 
 func New(lexer *lexer.Lexer) *Parser {
@@ -27,12 +27,12 @@ func New(lexer *lexer.Lexer) *Parser {
 
 func (p *Parser) Parse() ast.Program {
 	token.RegisterKeyWords()
-	token.RegisterPriorities()
 	program := ast.Program{
-		Node: ast.Node{
-			Type:  "Program",
-			Start: 0,
-			End:   int(p.lexer.Len()) - 1,
+		Span: ast.Span{
+			Type:     "Program",
+			Start:    0,
+			Position: p.lexer.Position(0),
+			Stop:     int(p.lexer.Len()) - 1,
 		},
 		Body: []ast.Statement{},
 	}
@@ -41,91 +41,155 @@ func (p *Parser) Parse() ast.Program {
 			p.nextToken()
 			continue
 		}
+		start := p.currentToken.Start()
+		errors := len(p.Errors)
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if len(p.Errors) == errors {
+			p.expectEndOfStatement()
+		}
+		if len(p.Errors) > errors {
+			p.synchronize()
+		} else if stmt != nil {
 			program.Body = append(program.Body, stmt)
 		}
+		if p.currentToken.Start() == start {
+			p.nextToken()
+		}
 	}
+	p.Errors = append(p.lexer.Errors, p.Errors...)
+	slices.SortStableFunc(p.Errors, func(a, b diagnostic.Diagnostic) int { return a.Pos.Offset - b.Pos.Offset })
 	return program
 }
 
 func (p *Parser) parseStatement() ast.Statement {
-	if p.currentToken.Lexeme() == "let" {
+	switch p.currentToken.Lexeme() {
+	case "let", "const":
 		return p.parseLetDeclaration()
-	} else if p.currentToken.Lexeme() == "fn" {
+	case "fn":
 		return p.parseFunctionDeclaration()
-	} else if p.peekToken.TokenType() == token.ASSIGN {
+	case "return":
+		return p.parseReturnExpression()
+	case "break":
+		return p.parseBreakStatement()
+	case "continue":
+		return p.parseContinueStatement()
+	}
+	if p.peekToken.TokenType() == token.ASSIGN {
 		return p.parseAssignment()
 	}
-	return p.parseExpression()
+	expr := p.parseExpression()
+	if expr != nil && p.currentToken.TokenType() == token.ASSIGN {
+		return p.parseIndexAssignment(expr)
+	}
+	return expr
+}
+
+func (p *Parser) parseIndexAssignment(target ast.Expression) ast.Statement {
+	// xs[0] = 10
+	left, ok := target.(ast.IndexExpression)
+	if !ok {
+		p.addError(diagnostic.InvalidAssignment, "wrong type in left side of assignment")
+		return nil
+	}
+	p.nextToken()
+	rightSide := p.parseValue()
+	if rightSide == nil {
+		return nil
+	}
+	return ast.IndexAssignmentStatement{
+		Left:     left,
+		Right:    rightSide,
+		Operator: "=",
+		Span: ast.Span{
+			Start:    left.Start,
+			Position: p.lexer.Position(left.Start),
+			Stop:     rightSide.End(),
+			Type:     "IndexAssignment",
+		},
+	}
 }
 
 func (p *Parser) parseLetDeclaration() ast.Statement {
 	// let a = 10
+	// const b = 20
 	start := p.currentToken.Start()
+	constant := p.currentToken.Lexeme() == "const"
 	if p.peekToken.TokenType() != token.IDENTIFIER {
-		p.addError("error: wrong type in left side of assignment")
+		p.addError(diagnostic.InvalidAssignment, "wrong type in left side of assignment")
 		return nil
 	}
 	p.nextToken()
+	p.checkName()
 	left := ast.Identifier{
 		Value: p.currentToken.Lexeme(),
-		Node: ast.Node{
-			Start: p.currentToken.Start(),
-			End:   p.currentToken.End(),
-			Type:  "Identifier",
+		Span: ast.Span{
+			Start:    p.currentToken.Start(),
+			Position: p.lexer.Position(p.currentToken.Start()),
+			Stop:     p.currentToken.End(),
+			Type:     "Identifier",
 		},
 	}
 	p.nextToken()
+	if p.currentToken.TokenType() != token.ASSIGN {
+		p.addError(diagnostic.MissingToken, "missing '=' in declaration of '%s'", left.Value)
+		return nil
+	}
+	p.nextToken()
+	stmt := p.parseRHS("let", left, start)
+	if let, ok := stmt.(ast.LetStatement); ok && constant {
+		let.Constant = true
+		return let
+	}
+	return stmt
+}
+
+// checkName reports an error when the current token, which declares or
+// assigns a name, is a reserved word. Parsing goes on as if the name were
+// allowed.
+func (p *Parser) checkName() {
+	if token.IsKeyword(p.currentToken.Lexeme()) {
+		p.addError(diagnostic.ReservedName, "'%s' is a reserved word and cannot be used as a name", p.currentToken.Lexeme())
+	}
+}
+
+func (p *Parser) parseFunctionDeclaration() ast.Expression {
+	fn := p.parseFunction()
 	p.nextToken()
-	return p.parseRHS("let", left, start)
+	return fn
 }
 
-func (p *Parser) parseFunctionDeclaration() ast.Statement {
+// parseFunction parses a named or anonymous function and leaves the current
+// token on its closing 'end'.
+func (p *Parser) parseFunction() ast.Expression {
 	// fn hello |a, b| -> a end
+	// fn |a| -> a * 2 end
 	start := p.currentToken.Start()
 	p.nextToken()
 	var name ast.Identifier
-	if p.currentToken.TokenType() != token.IDENTIFIER {
-		name = ast.Identifier{}
-		p.nextToken()
-	} else {
-		name = p.parseIdentifier("").(ast.Identifier)
-		p.nextToken()
+	if p.currentToken.TokenType() == token.IDENTIFIER {
+		p.checkName()
+		name = p.parseIdentifier().(ast.Identifier)
 		p.nextToken()
 	}
-
 	var parameters []ast.Identifier
-	for p.currentToken.TokenType() != token.BAR {
-		if p.currentToken.TokenType() == token.IDENTIFIER {
-			parameters = append(parameters, p.parseIdentifier("").(ast.Identifier))
-			p.nextToken()
-		} else {
-			p.nextToken()
-		}
-	}
-	p.nextToken()
-	p.nextToken()
-	for p.currentToken.TokenType() == token.NEWLINE {
+	if p.currentToken.TokenType() == token.BAR {
 		p.nextToken()
+		parameters = p.parseParameters()
+	} else {
+		p.addError(diagnostic.MissingToken, "missing '|' before function parameters")
 	}
-	var body []ast.Statement
-	for p.currentToken.Lexeme() != "end" {
-		for p.currentToken.TokenType() == token.NEWLINE {
-			p.nextToken()
-		}
-		body = append(body, p.parseStatement())
-		if p.currentToken.Lexeme() != "end" {
-			p.nextToken()
-		}
+	if p.currentToken.TokenType() == token.ARROW {
+		p.nextToken()
+	} else {
+		p.addError(diagnostic.MissingToken, "missing '->' before function body")
 	}
-	end := p.currentToken.End()
-	p.nextToken()
+	body := p.parseBlock("end")
 	return ast.FunctionExpression{
-		Node: ast.Node{
-			Start: start,
-			End:   end,
-			Type:  "FunctionExpression",
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.currentToken.End(),
+			Type:     "FunctionExpression",
 		},
 		Body:       body,
 		Parameters: parameters,
@@ -133,438 +197,1075 @@ func (p *Parser) parseFunctionDeclaration() ast.Statement {
 	}
 }
 
+// parseParameters parses the parameters of a function up to and including
+// the '|' closing them.
+func (p *Parser) parseParameters() []ast.Identifier {
+	var parameters []ast.Identifier
+	for p.currentToken.TokenType() != token.BAR {
+		switch p.currentToken.TokenType() {
+		case token.EOF, token.NEWLINE, token.ARROW:
+			p.addError(diagnostic.MissingToken, "missing '|' after function parameters")
+			return parameters
+		case token.IDENTIFIER:
+			p.checkName()
+			parameters = append(parameters, p.parseIdentifier().(ast.Identifier))
+		case token.COMMA:
+		default:
+			p.addError(diagnostic.UnexpectedSyntax, "unexpected %s in function parameters", describe(p.currentToken))
+		}
+		p.nextToken()
+	}
+	p.nextToken()
+	return parameters
+}
+
 func (p *Parser) parseAssignment() ast.Statement {
 	// a = 10
 	start := p.currentToken.Start()
 	if p.currentToken.TokenType() != token.IDENTIFIER {
-		p.addError("error: wrong type in left side of assignment")
+		p.addError(diagnostic.InvalidAssignment, "wrong type in left side of assignment")
 		return nil
 	}
+	p.checkName()
 	left := ast.Identifier{
 		Value: p.currentToken.Lexeme(),
-		Node: ast.Node{
-			Start: start,
-			End:   p.currentToken.End(),
-			Type:  "Identifier",
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.currentToken.End(),
+			Type:     "Identifier",
 		},
 	}
 	p.nextToken()
 	return p.parseRHS("assign", left, start)
 }
 
-func (p *Parser) parseExpression(args ...string) ast.Statement {
+func (p *Parser) parseExpression() ast.Expression {
 	switch p.currentToken.Lexeme() {
 	case "if":
 		return p.parseIfExpression()
-	case "return":
-		return p.parseReturnExpression()
+	case "while":
+		return p.parseWhileExpression()
+	case "for":
+		return p.parseForExpression()
+	case "match":
+		return p.parseMatchExpression()
 	default:
-		postfix := p.convertToPostFixNotation(args...)
-		if len(postfix) == 1 {
-			return postfix[0]
+		return p.parseOperation(token.LOWEST)
+	}
+}
+
+// parseOperation parses an expression made of operands and the operators
+// binding tighter than precedence. It leaves the current token on the first
+// one after the expression.
+func (p *Parser) parseOperation(precedence token.Precedence) ast.Expression {
+	left := p.parsePrefix()
+	for left != nil && precedence < p.currentToken.Precedence() {
+		left = p.parseInfix(left)
+	}
+	return left
+}
+
+// parsePrefix parses an operand, or a prefix operator applied to one.
+func (p *Parser) parsePrefix() ast.Expression {
+	var operand ast.Expression
+	switch p.currentToken.TokenType() {
+	case token.MINUS, token.NOT:
+		return p.parseUnary()
+	case token.LPAREN:
+		operand = p.parseParenthesized()
+	case token.LBRACKET:
+		operand = p.parseListLiteral()
+	case token.LBRACE:
+		operand = p.parseMapLiteral()
+	case token.INTEGER, token.FLOAT, token.DECIMAL, token.STRING:
+		operand = p.parseLiteral()
+	case token.INVALID:
+		// the lexer has reported it, so it stands in for the literal it
+		// should have been and the rest of the statement is still checked
+		operand = p.parseLiteral()
+	case token.INTERPOLATED:
+		operand = p.parseInterpolatedString()
+	case token.IDENTIFIER:
+		switch {
+		case p.currentToken.Lexeme() == "fn":
+			operand = p.parseFunction()
+		case p.startsOperand():
+			operand = p.parseIdentifier()
 		}
-		return p.createASTFromPostfixExpression(postfix)
 	}
+	if operand == nil {
+		if !p.startsOperand() {
+			p.addError(diagnostic.UnexpectedSyntax, "expected an expression, found %s", describe(p.currentToken))
+		}
+		return nil
+	}
+	p.nextToken()
+	return operand
 }
 
-func (p *Parser) parseFunctionEvaluation() ast.Statement {
-	// hello(a, b)
-	name := p.parseIdentifier("").(ast.Identifier)
+// startsOperand reports whether the current token can start an operand.
+// Reserved words cannot, except for the booleans and 'fn'.
+func (p *Parser) startsOperand() bool {
+	switch p.currentToken.TokenType() {
+	case token.MINUS, token.NOT, token.LPAREN, token.LBRACKET, token.LBRACE,
+		token.INTEGER, token.FLOAT, token.DECIMAL, token.STRING, token.INTERPOLATED, token.INVALID:
+		return true
+	case token.IDENTIFIER:
+		switch lexeme := p.currentToken.Lexeme(); lexeme {
+		case "true", "false", "fn":
+			return true
+		default:
+			return !token.IsKeyword(lexeme)
+		}
+	}
+	return false
+}
+
+// parseUnary parses a '-' or '!' applied to the operand following it. On a
+// literal or a name, the operator is kept by the node itself.
+func (p *Parser) parseUnary() ast.Expression {
+	// -x, !done, -(a + b), -f(x)
+	start := p.currentToken.Start()
+	operator := p.currentToken.Lexeme()
 	p.nextToken()
-	var args []ast.Statement
+	if !p.startsOperand() {
+		p.addError(diagnostic.UnexpectedSyntax, "missing operand for '%s'", operator)
+		return nil
+	}
+	operand := p.parseOperation(token.PREFIX)
+	switch value := operand.(type) {
+	case nil:
+		return nil
+	case ast.Literal:
+		if value.UnaryOp == "" {
+			value.UnaryOp = operator
+			return value
+		}
+	case ast.Identifier:
+		if value.UnaryOp == "" {
+			value.UnaryOp = operator
+			return value
+		}
+	}
+	return ast.UnaryExpression{
+		Value:    operand,
+		Operator: operator,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.previousToken.End(),
+			Type:     "UnaryExpression",
+		},
+	}
+}
+
+// parseInfix parses the operator following left together with its right
+// operand, or the call or index applied to left.
+func (p *Parser) parseInfix(left ast.Expression) ast.Expression {
+	var postfix ast.Expression
+	switch p.currentToken.TokenType() {
+	case token.LPAREN:
+		postfix = p.parseFunctionEvaluation(left)
+	case token.LBRACKET:
+		postfix = p.parseIndexExpression(left)
+	default:
+		return p.parseBinary(left)
+	}
+	if postfix == nil {
+		return nil
+	}
 	p.nextToken()
+	return postfix
+}
 
-	for p.currentToken.TokenType() != token.RPAREN {
-		if p.peekToken.TokenType() == token.COMMA {
-			args = append(args, p.callAppropriateFunction(p.currentToken.TokenType(), ""))
-			p.nextToken()
-			p.nextToken()
-			continue
-		} else if p.peekToken.TokenType() == token.RPAREN {
-			args = append(args, p.callAppropriateFunction(p.currentToken.TokenType(), ""))
-			p.nextToken()
+// parseBinary parses the binary operator following left and its right
+// operand. '**' is right associative and every other operator is left
+// associative.
+func (p *Parser) parseBinary(left ast.Expression) ast.Expression {
+	operator := p.currentToken
+	precedence := operator.Precedence()
+	if operator.TokenType() == token.STARSTAR {
+		precedence--
+	}
+	p.nextToken()
+	if !p.startsOperand() {
+		p.addError(diagnostic.UnexpectedSyntax, "missing operand for '%s'", operator.Lexeme())
+		return nil
+	}
+	right := p.parseOperation(precedence)
+	if right == nil {
+		return nil
+	}
+	node := ast.Span{
+		Start:    left.Pos().Offset,
+		Position: left.Pos(),
+		Stop:     right.End(),
+	}
+	switch operator.Lexeme() {
+	case "..", "..<":
+		node.Type = "RangeExpression"
+		return ast.RangeExpression{
+			From:      left,
+			To:        right,
+			Inclusive: operator.Lexeme() == "..",
+			Span:      node,
+		}
+	case "by":
+		rng, ok := left.(ast.RangeExpression)
+		if !ok || rng.Step != nil {
+			p.errorAt(diagnostic.UnexpectedSyntax, operator.Start(), operator.End(), "'by' must follow a range")
+			return nil
+		}
+		rng.Step = right
+		rng.Stop = node.Stop
+		return rng
+	}
+	node.Type = "BinaryExpression"
+	return ast.BinaryExpression{
+		Left:     left,
+		Right:    right,
+		Operator: operator.Lexeme(),
+		Span:     node,
+	}
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	// match shape do
+	//   (0, 0) -> "origin"
+	//   (x, 0) if x > 0 -> "positive x axis"
+	//   _ -> "elsewhere"
+	// end
+	start := p.currentToken.Start()
+	p.nextToken()
+	subject := p.parseExpression()
+	p.expectDo()
+	var clauses []ast.MatchClause
+	for {
+		p.skipNewlines()
+		if p.currentToken.Lexeme() == "end" {
+			break
+		}
+		if p.currentToken.TokenType() == token.EOF {
+			p.addError(diagnostic.MissingToken, "missing 'end'")
+			return nil
+		}
+		clauseStart := p.currentToken.Start()
+		errors := len(p.Errors)
+		clause := p.parseMatchClause()
+		if clause != nil {
+			p.expectEndOfStatement()
+		}
+		if clause == nil || len(p.Errors) > errors {
+			p.synchronize()
+			if p.currentToken.Start() == clauseStart {
+				p.nextToken()
+			}
 			continue
 		}
-		args = append(args, p.parseExpression([]string{"call"}...))
-		if p.currentToken.TokenType() != token.RPAREN {
-			p.nextToken()
+		clauses = append(clauses, *clause)
+	}
+	end := p.currentToken.End()
+	p.nextToken()
+	return ast.MatchExpression{
+		Subject: subject,
+		Clauses: clauses,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     end,
+			Type:     "MatchExpression",
+		},
+	}
+}
+
+func (p *Parser) parseMatchClause() *ast.MatchClause {
+	start := p.currentToken.Start()
+	pattern := p.parseExpression()
+	if pattern == nil {
+		return nil
+	}
+	var guard ast.Expression
+	if p.currentToken.Lexeme() == "if" {
+		p.nextToken()
+		guard = p.parseExpression()
+		if guard == nil {
+			return nil
 		}
 	}
+	if p.currentToken.TokenType() != token.ARROW {
+		p.addError(diagnostic.MissingToken, "missing '->' in match clause")
+		return nil
+	}
+	p.nextToken()
+	body := p.parseStatement()
+	if body == nil {
+		return nil
+	}
+	return &ast.MatchClause{
+		Pattern: pattern,
+		Guard:   guard,
+		Body:    body,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     body.End(),
+			Type:     "MatchClause",
+		},
+	}
+}
 
-	fnEval := ast.FunctionEvaluation{
-		Node: ast.Node{
-			Start: name.Start,
-			End:   p.currentToken.Start(),
-			Type:  "FunctionEvaluation",
+func (p *Parser) parseWhileExpression() ast.Expression {
+	// while i < 10 do i = i + 1 end
+	start := p.currentToken.Start()
+	p.nextToken()
+	test := p.parseExpression()
+	p.expectDo()
+	body := p.parseBlock("end")
+	end := p.currentToken.End()
+	p.nextToken()
+	return ast.WhileExpression{
+		Test: test,
+		Body: body,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     end,
+			Type:     "WhileExpression",
 		},
-		Arguments: args,
-		Name:      name,
 	}
-	return fnEval
+}
 
+func (p *Parser) parseForExpression() ast.Expression {
+	// for i in 1..10 do total = total + i end
+	start := p.currentToken.Start()
+	p.nextToken()
+	var variable ast.Identifier
+	var iterable ast.Expression
+	if p.currentToken.TokenType() != token.IDENTIFIER {
+		p.addError(diagnostic.MissingToken, "missing loop variable after 'for'")
+	} else {
+		p.checkName()
+		variable = p.parseIdentifier().(ast.Identifier)
+		p.nextToken()
+		if p.currentToken.Lexeme() != "in" {
+			p.addError(diagnostic.MissingToken, "missing 'in' symbol")
+		} else {
+			p.nextToken()
+			iterable = p.parseExpression()
+		}
+	}
+	p.expectDo()
+	body := p.parseBlock("end")
+	end := p.currentToken.End()
+	p.nextToken()
+	return ast.ForExpression{
+		Variable: variable,
+		Iterable: iterable,
+		Body:     body,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     end,
+			Type:     "ForExpression",
+		},
+	}
 }
 
-func (p *Parser) isBinaryOperator(token token.Token) bool {
-	switch token.TokenType() {
-	case "PLUS", "MINUS", "STAR", "SLASH", "EQ", "NE", "GT", "LT", "GE", "LE", "MOD":
-		return true
-	default:
-		if token.Lexeme() == "and" || token.Lexeme() == "or" {
-			return true
+func (p *Parser) parseBreakStatement() ast.Statement {
+	// break
+	// break total
+	stmt := ast.BreakStatement{
+		Span: ast.Span{
+			Start:    p.currentToken.Start(),
+			Position: p.lexer.Position(p.currentToken.Start()),
+			Stop:     p.currentToken.End(),
+			Type:     "BreakStatement",
+		},
+	}
+	p.nextToken()
+	if !p.isEndOfExpression() {
+		stmt.Value = p.parseExpression()
+		if stmt.Value == nil {
+			return nil
 		}
-		return false
+		stmt.Stop = stmt.Value.End()
 	}
+	return stmt
 }
 
-func (p *Parser) parseReturnExpression() ast.Statement {
-	start := p.currentToken.Start()
+func (p *Parser) parseContinueStatement() ast.Statement {
+	stmt := ast.ContinueStatement{
+		Span: ast.Span{
+			Start:    p.currentToken.Start(),
+			Position: p.lexer.Position(p.currentToken.Start()),
+			Stop:     p.currentToken.End(),
+			Type:     "ContinueStatement",
+		},
+	}
 	p.nextToken()
-	if p.peekToken.TokenType() != token.NEWLINE {
-		result := p.parseStatement()
-		return ast.ReturnStatement{
-			Value: result,
-			Node: ast.Node{
-				Start: start,
-				End:   p.getEndOfStatement(result),
-				Type:  "returnstatement",
-			},
+	return stmt
+}
+
+// parseBlock parses statements up to, but not including, the first of the
+// given keywords. A block left open at the end of the input is missing its
+// 'end', which closes every block whatever else may end it early.
+func (p *Parser) parseBlock(terminators ...string) []ast.Statement {
+	var body []ast.Statement
+	for !slices.Contains(terminators, p.currentToken.Lexeme()) {
+		if p.currentToken.TokenType() == token.EOF {
+			p.addError(diagnostic.MissingToken, "missing 'end'")
+			return body
+		}
+		if p.currentToken.TokenType() == token.NEWLINE {
+			p.nextToken()
+			continue
+		}
+		start := p.currentToken.Start()
+		errors := len(p.Errors)
+		stmt := p.parseStatement()
+		if len(p.Errors) == errors {
+			p.expectEndOfStatement()
+		}
+		if len(p.Errors) > errors {
+			p.synchronize()
+		} else if stmt != nil {
+			body = append(body, stmt)
+		}
+		if p.currentToken.Start() == start {
+			p.nextToken()
 		}
 	}
+	return body
+}
+
+// expectEndOfStatement reports a token left over on the line after a
+// statement.
+func (p *Parser) expectEndOfStatement() {
+	switch {
+	case p.atEndOfStatement():
+	case p.startsOperand():
+		p.addError(diagnostic.UnexpectedSyntax, "missing operator before this expression")
+	case p.currentToken.TokenType() == token.RPAREN:
+		p.addError(diagnostic.UnexpectedSyntax, "unbalanced parenthesis")
+	default:
+		p.addError(diagnostic.UnexpectedSyntax, "unexpected %s", describe(p.currentToken))
+	}
+}
+
+// atEndOfStatement reports whether the current token is a newline or a
+// keyword closing a block, which is where a statement ends.
+func (p *Parser) atEndOfStatement() bool {
 	switch p.currentToken.TokenType() {
-	case token.IDENTIFIER:
-		v := p.parseIdentifier("")
-		return ast.ReturnStatement{
-			Value: v,
-			Node: ast.Node{
-				Start: start,
-				End:   v.(ast.Identifier).End,
-				Type:  "returnstatement",
-			},
+	case token.NEWLINE, token.EOF:
+		return true
+	}
+	switch p.currentToken.Lexeme() {
+	case "end", "else", "elif":
+		return true
+	}
+	return false
+}
+
+// synchronize skips the rest of a statement that failed to parse, up to the
+// next newline or keyword closing a block, so that parsing can go on from
+// there and report any further errors.
+func (p *Parser) synchronize() {
+	for !p.atEndOfStatement() {
+		p.nextToken()
+	}
+}
+
+// expectDo moves past the 'do' opening a block. When it is missing, the rest
+// of the line up to a 'do' is skipped, so the block is still parsed.
+func (p *Parser) expectDo() {
+	if p.currentToken.Lexeme() != "do" {
+		p.addError(diagnostic.MissingToken, "missing 'do' symbol")
+		for p.currentToken.Lexeme() != "do" && p.currentToken.TokenType() != token.NEWLINE && p.currentToken.TokenType() != token.EOF {
+			p.nextToken()
 		}
-	case token.STRING, token.INTEGER, token.FLOAT:
-		stmt := ast.ReturnStatement{
-			Value: ast.Literal{
-				Node: ast.Node{
-					Start: p.currentToken.Start(),
-					End:   p.currentToken.End(),
-					Type:  "Literal",
-				},
-				Value: p.currentToken.Value(),
-			},
-			Node: ast.Node{
-				Start: start,
-				End:   p.currentToken.End(),
-				Type:  "ReturnStatement",
-			},
+		if p.currentToken.Lexeme() != "do" {
+			return
 		}
-		p.nextToken()
-		return stmt
+	}
+	p.nextToken()
+}
 
+// isEndOfExpression reports whether the current token closes the
+// expression being parsed.
+func (p *Parser) isEndOfExpression() bool {
+	switch p.currentToken.TokenType() {
+	case token.NEWLINE, token.EOF:
+		return true
+	}
+	switch p.currentToken.Lexeme() {
+	case "end", "else", "elif", "do":
+		return true
 	}
-	p.addError("error: unsupported return type, must be literal or identifier")
-	return nil
+	return false
 }
 
-func (p *Parser) parseIfExpression() ast.Statement {
-	// if 10 < 20 do a else b
-	start := p.currentToken.Start()
-	var test any
+func (p *Parser) parseFunctionEvaluation(callee ast.Expression) ast.Expression {
+	// hello(a, b)
+	// adder(1)(2)
 	p.nextToken()
-	if p.isBinaryOperator(p.peekToken) {
-		test = p.parseExpression()
-	} else if p.peekToken.Lexeme() == "do" {
-		test = p.parseIdentifier("")
-		p.nextToken()
+	var args []ast.Expression
+	for {
+		p.skipNewlines()
+		if p.currentToken.TokenType() == token.RPAREN {
+			break
+		}
+		arg := p.parseExpression()
+		if arg == nil {
+			return nil
+		}
+		args = append(args, arg)
+		p.skipNewlinesBefore(token.COMMA, token.RPAREN)
+		if p.currentToken.TokenType() == token.COMMA {
+			p.nextToken()
+			continue
+		}
+		if p.currentToken.TokenType() != token.RPAREN {
+			p.addError(diagnostic.MissingToken, "missing ')' in function call")
+			return nil
+		}
+	}
+
+	fnEval := ast.FunctionEvaluation{
+		Span: ast.Span{
+			Start:    callee.Pos().Offset,
+			Position: callee.Pos(),
+			Stop:     p.currentToken.Start(),
+			Type:     "FunctionEvaluation",
+		},
+		Arguments: args,
 	}
-	if keyword := token.GetKeyword(p.currentToken.Lexeme()); keyword != "do" {
-		p.addError("error: missing 'do' symbol")
+	if name, ok := callee.(ast.Identifier); ok {
+		fnEval.Name = name
+	} else {
+		fnEval.Callee = callee
+	}
+	return fnEval
+}
+
+func (p *Parser) parseReturnExpression() ast.Statement {
+	// return a + b
+	start := p.currentToken.Start()
+	p.nextToken()
+	result := p.parseExpression()
+	if result == nil {
 		return nil
 	}
+	return ast.ReturnStatement{
+		Value: result,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     result.End(),
+			Type:     "ReturnStatement",
+		},
+	}
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+	// if 10 < 20 do a else b
+	// if a do b elif c do d else e
+	start := p.currentToken.Start()
+	var test ast.Expression
 	p.nextToken()
-	consequent := p.parseExpression()
+	test = p.parseExpression()
+	p.expectDo()
+	if p.currentToken.TokenType() == token.NEWLINE {
+		consequent := p.parseBlockStatement("else", "elif", "end")
+		return p.parseIfBranches(start, test, consequent)
+	}
+	errors := len(p.Errors)
+	consequent := p.parseStatement()
+	recovered := len(p.Errors) > errors
+	if recovered {
+		// go on with an 'else' on the same line
+		p.synchronize()
+	}
 	end := p.currentToken.Start() - 1
-	if keyword := token.GetKeyword(p.currentToken.Lexeme()); keyword != "else" {
+	var alternate ast.Statement
+	errors = len(p.Errors)
+	switch token.GetKeyword(p.currentToken.Lexeme()) {
+	case "elif":
+		alternate = p.parseIfExpression()
+	case "else":
+		p.nextToken()
+		alternate = p.parseStatement()
+	default:
 		return ast.IfBlock{
-			Node: ast.Node{
-				Start: start,
-				End:   end,
-				Type:  "IfExpression",
+			Span: ast.Span{
+				Start:    start,
+				Position: p.lexer.Position(start),
+				Stop:     end,
+				Type:     "IfExpression",
 			},
 			Consequent: consequent,
 			Test:       test,
 		}
 	}
-	p.nextToken()
-	alternate := p.parseExpression()
+	if recovered && len(p.Errors) == errors {
+		// the statement already has an error, so the caller no longer
+		// checks what follows the alternate
+		p.expectEndOfStatement()
+	}
 	return ast.IfElseBlock{
 		Consequent: consequent,
 		Alternate:  alternate,
-		Node: ast.Node{
-			Start: start,
-			End:   p.currentToken.Start() - 1,
-			Type:  "IfElseExpression",
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.currentToken.Start() - 1,
+			Type:     "IfElseExpression",
 		},
 		Test: test,
 	}
-
 }
 
-func (p *Parser) parseRHS(kind string, left ast.Identifier, start int) ast.Statement {
-	if p.currentToken.Lexeme() == "fn" {
-		rightSide := p.parseFunctionDeclaration()
-		return ast.LetStatement{
-			Left:  left,
-			Right: rightSide,
-			Node: ast.Node{
-				Start: start,
-				End:   rightSide.(ast.FunctionExpression).End,
-				Type:  "LetStatement",
+// parseIfBranches parses whatever follows the consequent of a block form if
+// expression, up to and including the 'end' shared by the whole chain:
+//
+//	if a do
+//	  ...
+//	elif b do
+//	  ...
+//	else
+//	  ...
+//	end
+func (p *Parser) parseIfBranches(start int, test ast.Expression, consequent ast.Statement) ast.Expression {
+	if p.currentToken.TokenType() == token.EOF {
+		return nil
+	}
+	switch p.currentToken.Lexeme() {
+	case "end":
+		end := p.currentToken.End()
+		p.nextToken()
+		return ast.IfBlock{
+			Span: ast.Span{
+				Start:    start,
+				Position: p.lexer.Position(start),
+				Stop:     end,
+				Type:     "IfExpression",
 			},
-			Operator: "=",
+			Consequent: consequent,
+			Test:       test,
 		}
-	} else if p.currentToken.Lexeme() == "if" {
-		rightSide := p.parseIfExpression()
-		return ast.LetStatement{
-			Left:  left,
-			Right: rightSide,
-			Node: ast.Node{
-				Start: start,
-				End:   p.getEndOfStatement(rightSide),
-				Type:  "LetStatement",
-			},
-			Operator: "=",
+	case "else":
+		p.nextToken()
+		if p.currentToken.Lexeme() != "if" {
+			alternate := p.parseBlockStatement("end")
+			end := p.currentToken.End()
+			p.nextToken()
+			return ast.IfElseBlock{
+				Consequent: consequent,
+				Alternate:  alternate,
+				Span: ast.Span{
+					Start:    start,
+					Position: p.lexer.Position(start),
+					Stop:     end,
+					Type:     "IfElseExpression",
+				},
+				Test: test,
+			}
 		}
 	}
-	var tp string
-	if kind == "let" {
-		tp = "LetStatement"
-	} else {
-		tp = "Assignment"
+	// elif c do, else if c do
+	branchStart := p.currentToken.Start()
+	p.nextToken()
+	branchTest := p.parseExpression()
+	p.expectDo()
+	alternate := p.parseIfBranches(branchStart, branchTest, p.parseBlockStatement("else", "elif", "end"))
+	if alternate == nil {
+		return nil
+	}
+	return ast.IfElseBlock{
+		Consequent: consequent,
+		Alternate:  alternate,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     alternate.End(),
+			Type:     "IfElseExpression",
+		},
+		Test: test,
+	}
+}
+
+func (p *Parser) parseBlockStatement(terminators ...string) ast.Statement {
+	p.skipNewlines()
+	start := p.currentToken.Start()
+	body := p.parseBlock(terminators...)
+	end := start
+	if len(body) > 0 {
+		end = body[len(body)-1].End()
+	}
+	return ast.BlockStatement{
+		Body: body,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     end,
+			Type:     "BlockStatement",
+		},
+	}
+}
+
+func (p *Parser) parseRHS(kind string, left ast.Identifier, start int) ast.Statement {
+	if kind != "let" {
 		p.nextToken()
 	}
-	rightSide := p.parseExpression()
+	rightSide := p.parseValue()
+	if rightSide == nil {
+		return nil
+	}
 	if kind == "let" {
 		return ast.LetStatement{
 			Left:     left,
 			Right:    rightSide,
 			Operator: "=",
-			Node: ast.Node{
-				Start: start,
-				End:   p.getEndOfStatement(rightSide),
-				Type:  tp,
-			},
-		}
-
-	} else {
-		return ast.AssignmentStatement{
-			Left:     left,
-			Right:    rightSide,
-			Operator: "=",
-			Node: ast.Node{
-				Start: start,
-				End:   p.getEndOfStatement(rightSide),
-				Type:  tp,
+			Span: ast.Span{
+				Start:    start,
+				Position: p.lexer.Position(start),
+				Stop:     rightSide.End(),
+				Type:     "LetStatement",
 			},
 		}
 	}
+	return ast.AssignmentStatement{
+		Left:     left,
+		Right:    rightSide,
+		Operator: "=",
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     rightSide.End(),
+			Type:     "Assignment",
+		},
+	}
+}
 
+// parseValue parses the right side of a binding.
+func (p *Parser) parseValue() ast.Expression {
+	switch p.currentToken.Lexeme() {
+	case "fn":
+		return p.parseFunctionDeclaration()
+	case "if":
+		return p.parseIfExpression()
+	}
+	return p.parseExpression()
 }
-func (p *Parser) createASTFromPostfixExpression(tokens []any) ast.Statement {
-	stack := []any{}
-	for _, val := range tokens {
-		if reflect.TypeOf(val).Kind() == reflect.String {
-			right := stack[len(stack)-1]
-			left := stack[len(stack)-2]
-			bExp := ast.BinaryExpression{
-				Left:     left,
-				Right:    right,
-				Operator: val.(string),
-				Node: ast.Node{
-					Start: p.getStartOfStatement(left),
-					End:   p.getEndOfStatement(right),
-					Type:  "BinaryExpression",
-				},
-			}
-			stack = stack[0 : len(stack)-2]
-			stack = append(stack, bExp)
 
-		} else {
-			stack = append(stack, val)
+// parseParenthesized parses a parenthesized expression, which is a tuple
+// literal when it is empty or contains a comma.
+func (p *Parser) parseParenthesized() ast.Expression {
+	// (1 + 2), (1, "one"), (1,), ()
+	start := p.currentToken.Start()
+	p.nextToken()
+	elements := []ast.Expression{}
+	tuple := false
+	for {
+		p.skipNewlines()
+		if p.currentToken.TokenType() == token.RPAREN {
+			break
+		}
+		element := p.parseExpression()
+		if element == nil {
+			return nil
+		}
+		elements = append(elements, element)
+		p.skipNewlinesBefore(token.COMMA, token.RPAREN)
+		if p.currentToken.TokenType() == token.COMMA {
+			tuple = true
+			p.nextToken()
+			continue
+		}
+		if p.currentToken.TokenType() != token.RPAREN {
+			p.addError(diagnostic.UnexpectedSyntax, "unbalanced parenthesis")
+			return nil
 		}
 	}
-	if len(stack) == 1 {
-		return stack[0]
+	if len(elements) == 1 && !tuple {
+		return elements[0]
+	}
+	return ast.TupleLiteral{
+		Elements: elements,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.currentToken.End(),
+			Type:     "TupleLiteral",
+		},
 	}
-	p.addError("error: syntax error!")
-	return nil
 }
 
-func (p *Parser) convertToPostFixNotation(calledBy ...string) []any {
-	queue := []any{}
-	stack := []any{}
-	var prevToken any
-	tokens := []string{"NEWLINE", "EOF", "COMMA"}
-	if len(calledBy) > 0 {
-		tokens = append(tokens, "RPAREN")
-	}
-	lexemes := []string{"else", "end", "do"}
-	for (!slices.Contains(tokens, p.currentToken.TokenType())) && (!slices.Contains(lexemes, p.currentToken.Lexeme())) {
-		current := p.currentToken
-		if (current.TokenType() == token.MINUS || current.TokenType() == token.NOT) && (prevToken == nil || prevToken == "(") {
-			p.nextToken()
-			queue = append(queue, p.callAppropriateFunction(p.currentToken.TokenType(), current.Lexeme()))
+func (p *Parser) parseListLiteral() ast.Expression {
+	// [1, 2, 3]
+	start := p.currentToken.Start()
+	p.nextToken()
+	elements := []ast.Expression{}
+	for {
+		p.skipNewlines()
+		if p.currentToken.TokenType() == token.RBRACKET {
+			break
+		}
+		element := p.parseExpression()
+		if element == nil {
+			return nil
+		}
+		elements = append(elements, element)
+		p.skipNewlinesBefore(token.COMMA, token.RBRACKET)
+		if p.currentToken.TokenType() == token.COMMA {
 			p.nextToken()
 			continue
 		}
-		if (current.TokenType() == token.IDENTIFIER || current.TokenType() == token.INTEGER || current.TokenType() == token.FLOAT || current.TokenType() == token.STRING) && (current.Lexeme() != "and" && current.Lexeme() != "or") {
-			queue = append(queue, p.callAppropriateFunction(current.TokenType(), ""))
-		} else if p.isBinaryOperator(current) {
-			for {
-				if len(stack) == 0 {
-					break
-				}
-				topElm := stack[len(stack)-1]
-				if topElm == "(" {
-					break
-				}
-				p1 := token.GetPriority(string(current.Lexeme()))
-				p2 := token.GetPriority(topElm.(string))
-				if p2[0].(int) > p1[0].(int) || (p1[0].(int) == p2[0].(int) && p1[1] == "left") {
-					queue = append(queue, topElm)
-					stack = stack[0 : len(stack)-1]
-				} else {
-					break
-				}
-			}
+		if p.currentToken.TokenType() != token.RBRACKET {
+			p.addError(diagnostic.MissingToken, "missing ']' in list literal")
+			return nil
+		}
+	}
+	return ast.ListLiteral{
+		Elements: elements,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.currentToken.End(),
+			Type:     "ListLiteral",
+		},
+	}
+}
 
-			stack = append(stack, current.Lexeme())
-		} else if current.TokenType() == token.LPAREN {
-			stack = append(stack, p.currentToken.Lexeme())
-		} else {
-			for {
-				if len(stack) == 0 {
-					p.addError(fmt.Sprintf("error: unbalanced parenthesis"))
-					return nil
-				}
-				topElm := stack[len(stack)-1]
-				if topElm == "(" {
-					break
-				} else {
-					queue = append(queue, topElm)
-					stack = stack[0 : len(stack)-1]
-				}
-			}
-			stack = stack[0 : len(stack)-1]
+func (p *Parser) parseMapLiteral() ast.Expression {
+	// {"name": "atom", "age": 1}
+	start := p.currentToken.Start()
+	p.nextToken()
+	keys := []ast.Expression{}
+	values := []ast.Expression{}
+	for {
+		p.skipNewlines()
+		if p.currentToken.TokenType() == token.RBRACE {
+			break
+		}
+		key := p.parseExpression()
+		if key == nil {
+			return nil
+		}
+		if p.currentToken.TokenType() != token.COLON {
+			p.addError(diagnostic.MissingToken, "missing ':' after map key")
+			return nil
 		}
-		prevToken = p.currentToken.Lexeme()
 		p.nextToken()
-	}
-	for len(stack) != 0 {
-		topElm := stack[len(stack)-1]
-		if topElm == "(" {
-			p.addError("error: unbalanced parenthesis")
-			return []any{}
+		p.skipNewlines()
+		value := p.parseExpression()
+		if value == nil {
+			return nil
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+		p.skipNewlinesBefore(token.COMMA, token.RBRACE)
+		if p.currentToken.TokenType() == token.COMMA {
+			p.nextToken()
+			continue
+		}
+		if p.currentToken.TokenType() != token.RBRACE {
+			p.addError(diagnostic.MissingToken, "missing '}' in map literal")
+			return nil
 		}
-		queue = append(queue, topElm)
-		stack = stack[0 : len(stack)-1]
 	}
-	return queue
+	return ast.MapLiteral{
+		Keys:   keys,
+		Values: values,
+		Span: ast.Span{
+			Start:    start,
+			Position: p.lexer.Position(start),
+			Stop:     p.currentToken.End(),
+			Type:     "MapLiteral",
+		},
+	}
 }
 
-func (p *Parser) callAppropriateFunction(tokenType string, op string) ast.Statement {
-	switch tokenType {
-	case token.IDENTIFIER:
-		if p.peekToken.TokenType() == token.LPAREN {
-			return p.parseFunctionEvaluation()
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	// xs[1], xs[1:3], xs[:2], xs[1:]
+	p.nextToken()
+	var low, high ast.Expression
+	if p.currentToken.TokenType() == token.RBRACKET {
+		p.addError(diagnostic.MissingToken, "missing index")
+		return nil
+	}
+	if p.currentToken.TokenType() != token.COLON {
+		low = p.parseExpression()
+		if low == nil {
+			return nil
 		}
-		return p.parseIdentifier(op)
-	case token.FLOAT, token.INTEGER, token.STRING:
-		return p.parseLiteral(op)
-	default:
-		return p.parseExpression()
+	}
+	if p.currentToken.TokenType() == token.RBRACKET {
+		return ast.IndexExpression{
+			Left:  left,
+			Index: low,
+			Span: ast.Span{
+				Start:    left.Pos().Offset,
+				Position: left.Pos(),
+				Stop:     p.currentToken.End(),
+				Type:     "IndexExpression",
+			},
+		}
+	}
+	if p.currentToken.TokenType() != token.COLON {
+		p.addError(diagnostic.MissingToken, "missing ']' in index expression")
+		return nil
+	}
+	p.nextToken()
+	if p.currentToken.TokenType() != token.RBRACKET {
+		high = p.parseExpression()
+	}
+	if p.currentToken.TokenType() != token.RBRACKET {
+		p.addError(diagnostic.MissingToken, "missing ']' in slice expression")
+		return nil
+	}
+	return ast.SliceExpression{
+		Left: left,
+		Low:  low,
+		High: high,
+		Span: ast.Span{
+			Start:    left.Pos().Offset,
+			Position: left.Pos(),
+			Stop:     p.currentToken.End(),
+			Type:     "SliceExpression",
+		},
+	}
+}
+
+func (p *Parser) skipNewlines() {
+	for p.currentToken.TokenType() == token.NEWLINE {
+		p.nextToken()
 	}
 }
 
-func (p *Parser) parseLiteral(op string) ast.Statement {
+// skipNewlinesBefore skips newlines only when the token following them is
+// one of types. An element of a bracketed list can be followed by its comma
+// or closing bracket on the next line, while a missing bracket is reported at
+// the end of the line instead of swallowing the next statement.
+func (p *Parser) skipNewlinesBefore(types ...string) {
+	if p.currentToken.TokenType() != token.NEWLINE {
+		return
+	}
+	for p.peekToken.TokenType() == token.NEWLINE {
+		p.nextToken()
+	}
+	if slices.Contains(types, p.peekToken.TokenType()) {
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseLiteral() ast.Expression {
 	return ast.Literal{
-		Node: ast.Node{
-			Start: p.currentToken.Start(),
-			End:   p.currentToken.End(),
-			Type:  "Literal",
+		Span: ast.Span{
+			Start:    p.currentToken.Start(),
+			Position: p.lexer.Position(p.currentToken.Start()),
+			Stop:     p.currentToken.End(),
+			Type:     "Literal",
 		},
-		Value:   p.currentToken.Value(),
-		UnaryOp: op,
+		Value: p.currentToken.Value(),
 	}
+}
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	// "Hello #{name}, you are #{age + 1}"
+	parts := []ast.Expression{}
+	for _, part := range p.currentToken.Value().([]token.StringPart) {
+		if !part.Expression {
+			parts = append(parts, ast.Literal{
+				Span: ast.Span{
+					Start:    part.Offset,
+					Position: p.lexer.Position(part.Offset),
+					Stop:     part.End,
+					Type:     "Literal",
+				},
+				Value: part.Text,
+			})
+			continue
+		}
+		expr := p.parseEmbeddedExpression(part)
+		if expr == nil {
+			return nil
+		}
+		parts = append(parts, expr)
+	}
+	return ast.InterpolatedString{
+		Parts: parts,
+		Span: ast.Span{
+			Start:    p.currentToken.Start(),
+			Position: p.lexer.Position(p.currentToken.Start()),
+			Stop:     p.currentToken.End(),
+			Type:     "InterpolatedString",
+		},
+	}
+}
+
+// parseEmbeddedExpression parses the expression of a '#{...}' part of an
+// interpolated string with a parser of its own.
+func (p *Parser) parseEmbeddedExpression(part token.StringPart) ast.Expression {
+	embedded := New(p.lexer.Embedded(part.Offset, part.End+1))
+	embedded.skipNewlines()
+	if embedded.currentToken.TokenType() == token.EOF {
+		p.addError(diagnostic.EmptyInterpolation, "empty interpolation in string literal")
+		return nil
+	}
+	expr := embedded.parseExpression()
+	embedded.skipNewlines()
+	if expr != nil && embedded.currentToken.TokenType() != token.EOF {
+		embedded.addError(diagnostic.UnexpectedSyntax, "unexpected '%s' in string interpolation", embedded.currentToken.Lexeme())
+	}
+	if errors := append(embedded.lexer.Errors, embedded.Errors...); len(errors) > 0 {
+		p.Errors = append(p.Errors, errors...)
+		return nil
+	}
+	return expr
 }
 
-func (p *Parser) parseIdentifier(op string) ast.Statement {
+func (p *Parser) parseIdentifier() ast.Expression {
 	return ast.Identifier{
-		Node: ast.Node{
-			Start: p.currentToken.Start(),
-			End:   p.currentToken.End(),
-			Type:  "Identifier",
+		Span: ast.Span{
+			Start:    p.currentToken.Start(),
+			Position: p.lexer.Position(p.currentToken.Start()),
+			Stop:     p.currentToken.End(),
+			Type:     "Identifier",
 		},
-		Value:   p.currentToken.Lexeme(),
-		UnaryOp: op,
+		Value: p.currentToken.Lexeme(),
 	}
 }
 
-func (p *Parser) getStartOfStatement(stmt ast.Statement) int {
-	switch stmt.(type) {
-	case ast.Literal:
-		return stmt.(ast.Literal).Start
-	case ast.Identifier:
-		return stmt.(ast.Identifier).Start
-	case ast.BinaryExpression:
-		return stmt.(ast.BinaryExpression).Start
-	case ast.IfBlock:
-		return stmt.(ast.IfBlock).Start
-	case ast.IfElseBlock:
-		return stmt.(ast.IfElseBlock).Start
-	case ast.ReturnStatement:
-		return stmt.(ast.ReturnStatement).Start
-	case ast.LetStatement:
-		return stmt.(ast.LetStatement).Start
-	case ast.AssignmentStatement:
-		return stmt.(ast.AssignmentStatement).Start
-	case ast.FunctionEvaluation:
-		return stmt.(ast.FunctionEvaluation).Start
-	case ast.FunctionExpression:
-		return stmt.(ast.FunctionExpression).Start
-	}
-	return 0
-}
-func (p *Parser) getEndOfStatement(stmt ast.Statement) int {
-	switch stmt.(type) {
-	case ast.Literal:
-		return stmt.(ast.Literal).End
-	case ast.Identifier:
-		return stmt.(ast.Identifier).End
-	case ast.BinaryExpression:
-		return stmt.(ast.BinaryExpression).End
-	case ast.IfBlock:
-		return stmt.(ast.IfBlock).End
-	case ast.IfElseBlock:
-		return stmt.(ast.IfElseBlock).End
-	case ast.ReturnStatement:
-		return stmt.(ast.ReturnStatement).End
-	case ast.LetStatement:
-		return stmt.(ast.LetStatement).End
-	case ast.AssignmentStatement:
-		return stmt.(ast.AssignmentStatement).End
-	case ast.FunctionEvaluation:
-		return stmt.(ast.FunctionEvaluation).End
-	case ast.FunctionExpression:
-		return stmt.(ast.FunctionExpression).End
-	}
-	return 0
-}
 func (p *Parser) nextToken() {
+	p.previousToken = p.currentToken
 	p.currentToken = p.peekToken
 	p.peekToken = p.lexer.NextToken()
 }
 
-func (p *Parser) addError(error string) {
-	p.Errors = append(p.Errors, error)
+// addError records a syntax error at the current token.
+func (p *Parser) addError(code string, format string, args ...any) {
+	p.errorAt(code, p.currentToken.Start(), p.currentToken.End(), format, args...)
+}
+
+// errorAt records a syntax error spanning the offsets start to end. Only the
+// first error at an offset is kept.
+func (p *Parser) errorAt(code string, start, end int, format string, args ...any) {
+	if n := len(p.Errors); n > 0 && p.Errors[n-1].Pos.Offset == start {
+		return
+	}
+	p.Errors = append(p.Errors, diagnostic.New(code, p.lexer.Position(start), end, format, args...))
+}
+
+// describe names a token in an error message.
+func describe(tok token.Token) string {
+	switch tok.TokenType() {
+	case token.NEWLINE:
+		return "end of line"
+	case token.EOF:
+		return "end of input"
+	case token.STRING, token.INTERPOLATED:
+		return "string"
+	}
+	return "'" + tok.Lexeme() + "'"
 }
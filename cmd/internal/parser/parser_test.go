@@ -6,6 +6,7 @@ import (
 
 	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
 	"github.com/iamBharatManral/atom.git/cmd/internal/lexer"
+	"github.com/iamBharatManral/atom.git/cmd/internal/token"
 )
 
 func TestLiteralsAndExpressions(t *testing.T) {
@@ -17,10 +18,10 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "integer literal 1933", want: []ast.Statement{
 			ast.Literal{
 				Value: 1933,
-				Node: ast.Node{
+				Span: ast.Span{
 					Type:  "Literal",
 					Start: 0,
-					End:   3,
+					Stop:  3,
 				},
 				UnaryOp: "",
 			},
@@ -28,10 +29,10 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "negative number", want: []ast.Statement{
 			ast.Literal{
 				Value: 10,
-				Node: ast.Node{
+				Span: ast.Span{
 					Type:  "Literal",
 					Start: 2,
-					End:   3,
+					Stop:  3,
 				},
 				UnaryOp: "-",
 			},
@@ -39,21 +40,57 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "not '!' operator", want: []ast.Statement{
 			ast.Identifier{
 				Value: "true",
-				Node: ast.Node{
+				Span: ast.Span{
 					Type:  "Identifier",
 					Start: 1,
-					End:   4,
+					Stop:  4,
 				},
 				UnaryOp: "!",
 			},
 		}, input: "!true"},
+		{name: "unary minus on a parenthesized expression", want: []ast.Statement{
+			ast.UnaryExpression{
+				Value: ast.BinaryExpression{
+					Left:     ast.Identifier{Value: "a", Span: ast.Span{Type: "Identifier", Start: 2, Stop: 2}},
+					Right:    ast.Identifier{Value: "b", Span: ast.Span{Type: "Identifier", Start: 6, Stop: 6}},
+					Operator: "+",
+					Span:     ast.Span{Type: "BinaryExpression", Start: 2, Stop: 6},
+				},
+				Operator: "-",
+				Span:     ast.Span{Type: "UnaryExpression", Start: 0, Stop: 7},
+			},
+		}, input: "-(a + b)"},
+		{name: "unary minus on a call", want: []ast.Statement{
+			ast.UnaryExpression{
+				Value: ast.FunctionEvaluation{
+					Name:      ast.Identifier{Value: "f", Span: ast.Span{Type: "Identifier", Start: 1, Stop: 1}},
+					Arguments: []ast.Expression{ast.Identifier{Value: "x", Span: ast.Span{Type: "Identifier", Start: 3, Stop: 3}}},
+					Span:      ast.Span{Type: "FunctionEvaluation", Start: 1, Stop: 4},
+				},
+				Operator: "-",
+				Span:     ast.Span{Type: "UnaryExpression", Start: 0, Stop: 4},
+			},
+		}, input: "-f(x)"},
+		{name: "right associative power", want: []ast.Statement{
+			ast.BinaryExpression{
+				Left: ast.Literal{Value: 2, Span: ast.Span{Type: "Literal", Start: 0, Stop: 0}},
+				Right: ast.BinaryExpression{
+					Left:     ast.Literal{Value: 3, Span: ast.Span{Type: "Literal", Start: 5, Stop: 5}},
+					Right:    ast.Literal{Value: 2, Span: ast.Span{Type: "Literal", Start: 10, Stop: 10}},
+					Operator: "**",
+					Span:     ast.Span{Type: "BinaryExpression", Start: 5, Stop: 10},
+				},
+				Operator: "**",
+				Span:     ast.Span{Type: "BinaryExpression", Start: 0, Stop: 10},
+			},
+		}, input: "2 ** 3 ** 2"},
 		{name: "string literal", want: []ast.Statement{
 			ast.Literal{
 				Value: "hello world",
-				Node: ast.Node{
+				Span: ast.Span{
 					Type:  "Literal",
 					Start: 0,
-					End:   12,
+					Stop:  12,
 				},
 				UnaryOp: "",
 			},
@@ -62,25 +99,25 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "addtion of two numbers 12 + 13", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   1,
+						Stop:  1,
 					},
 					Value: 12,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 5,
-						End:   6,
+						Stop:  6,
 					},
 					Value: 13,
 				},
 				Operator: "+",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   6,
+					Stop:  6,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -88,25 +125,25 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "addtion of two numbers within bracket(12 + 13)", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 1,
-						End:   2,
+						Stop:  2,
 					},
 					Value: 12,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 6,
-						End:   7,
+						Stop:  7,
 					},
 					Value: 13,
 				},
 				Operator: "+",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 1,
-					End:   7,
+					Stop:  7,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -114,49 +151,49 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "multiplication of two numbers 5 * 9 and division of two numbers 96 / 4", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   0,
+						Stop:  0,
 					},
 					Value: 5,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 4,
-						End:   4,
+						Stop:  4,
 					},
 					Value: 9,
 				},
 				Operator: "*",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   4,
+					Stop:  4,
 					Type:  "BinaryExpression",
 				},
 			},
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 6,
-						End:   7,
+						Stop:  7,
 					},
 					Value: 96,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 11,
-						End:   11,
+						Stop:  11,
 					},
 					Value: 4,
 				},
 				Operator: "/",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 6,
-					End:   11,
+					Stop:  11,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -166,65 +203,65 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "multiplication of two numbers 5 * 9 and literal string hello with 1 and another binary expression", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   0,
+						Stop:  0,
 					},
 					Value: 5,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 4,
-						End:   4,
+						Stop:  4,
 					},
 					Value: 9,
 				},
 				Operator: "*",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   4,
+					Stop:  4,
 					Type:  "BinaryExpression",
 				},
 			},
 			ast.Literal{
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 6,
-					End:   18,
+					Stop:  18,
 					Type:  "Literal",
 				},
 				Value: "hello world",
 			},
 			ast.Literal{
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 20,
-					End:   20,
+					Stop:  20,
 					Type:  "Literal",
 				},
 				Value: 1,
 			},
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 22,
-						End:   22,
+						Stop:  22,
 					},
 					Value: 4,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 25,
-						End:   25,
+						Stop:  25,
 					},
 					Value: 5,
 				},
 				Operator: "*",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 22,
-					End:   25,
+					Stop:  25,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -236,25 +273,25 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "let declaration", want: []ast.Statement{
 			ast.LetStatement{
 				Left: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Identifier",
 						Start: 4,
-						End:   4,
+						Stop:  4,
 					},
 					Value: "a",
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 8,
-						End:   9,
+						Stop:  9,
 					},
 					Value: 10,
 				},
 				Operator: "=",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   9,
+					Stop:  9,
 					Type:  "LetStatement",
 				},
 			},
@@ -262,25 +299,25 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "assigment operation name = \"hello\"", want: []ast.Statement{
 			ast.AssignmentStatement{
 				Left: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Identifier",
 						Start: 0,
-						End:   3,
+						Stop:  3,
 					},
 					Value: "name",
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 7,
-						End:   13,
+						Stop:  13,
 					},
 					Value: "hello",
 				},
 				Operator: "=",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   13,
+					Stop:  13,
 					Type:  "Assignment",
 				},
 			},
@@ -288,41 +325,41 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "multiple arithmetic expressions", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   1,
+						Stop:  1,
 					},
 					Value: 51,
 				},
 				Right: ast.BinaryExpression{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "BinaryExpression",
 						Start: 5,
-						End:   10,
+						Stop:  10,
 					},
 					Operator: "*",
 					Left: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 5,
-							End:   6,
+							Stop:  6,
 							Type:  "Literal",
 						},
 						Value: 23,
 					},
 					Right: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 10,
-							End:   10,
+							Stop:  10,
 							Type:  "Literal",
 						},
 						Value: 4,
 					},
 				},
 				Operator: "+",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   10,
+					Stop:  10,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -330,25 +367,25 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "less than comparison between 2 numbers", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   1,
+						Stop:  1,
 					},
 					Value: 12,
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 5,
-						End:   6,
+						Stop:  6,
 					},
 					Value: 13,
 				},
 				Operator: "<",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   6,
+					Stop:  6,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -356,25 +393,25 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "greater than equal to between 2 strings", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   6,
+						Stop:  6,
 					},
 					Value: "hello",
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 11,
-						End:   15,
+						Stop:  15,
 					},
 					Value: "bye",
 				},
 				Operator: ">=",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   15,
+					Stop:  15,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -383,39 +420,39 @@ func TestLiteralsAndExpressions(t *testing.T) {
 			ast.IfBlock{
 				Test: ast.BinaryExpression{
 					Left: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Type:  "Literal",
 							Start: 3,
-							End:   4,
+							Stop:  4,
 						},
 						Value: 38,
 					},
 					Right: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Type:  "Literal",
 							Start: 9,
-							End:   11,
+							Stop:  11,
 						},
 						Value: 121,
 					},
 					Operator: "<=",
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 3,
-						End:   11,
+						Stop:  11,
 						Type:  "BinaryExpression",
 					},
 				},
 				Consequent: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 16,
-						End:   16,
+						Stop:  16,
 						Type:  "Literal",
 					},
 					Value: 1,
 				},
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   16,
+					Stop:  16,
 					Type:  "IfExpression",
 				},
 			},
@@ -424,47 +461,47 @@ func TestLiteralsAndExpressions(t *testing.T) {
 			ast.IfElseBlock{
 				Test: ast.BinaryExpression{
 					Left: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Type:  "Literal",
 							Start: 3,
-							End:   4,
+							Stop:  4,
 						},
 						Value: 38,
 					},
 					Right: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Type:  "Literal",
 							Start: 9,
-							End:   11,
+							Stop:  11,
 						},
 						Value: 121,
 					},
 					Operator: "<=",
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 3,
-						End:   11,
+						Stop:  11,
 						Type:  "BinaryExpression",
 					},
 				},
 				Consequent: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 16,
-						End:   16,
+						Stop:  16,
 						Type:  "Literal",
 					},
 					Value: 1,
 				},
 				Alternate: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 23,
-						End:   23,
+						Stop:  23,
 						Type:  "Literal",
 					},
 					Value: 2,
 				},
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   23,
+					Stop:  23,
 					Type:  "IfElseExpression",
 				},
 			},
@@ -472,57 +509,120 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		{name: "if else block with true keyword", want: []ast.Statement{
 			ast.IfElseBlock{
 				Test: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 3,
-						End:   6,
+						Stop:  6,
 						Type:  "Identifier",
 					},
 					Value: "true",
 				},
 				Consequent: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 11,
-						End:   11,
+						Stop:  11,
 						Type:  "Literal",
 					},
 					Value: 1,
 				},
 				Alternate: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 18,
-						End:   18,
+						Stop:  18,
 						Type:  "Literal",
 					},
 					Value: 2,
 				},
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   18,
+					Stop:  18,
 					Type:  "IfElseExpression",
 				},
 			},
 		}, input: `if true do 1 else 2`},
+		{name: "tuple literal", want: []ast.Statement{
+			ast.TupleLiteral{
+				Elements: []ast.Expression{
+					ast.Literal{
+						Span: ast.Span{
+							Start: 1,
+							Stop:  1,
+							Type:  "Literal",
+						},
+						Value: 1,
+					},
+					ast.Literal{
+						Span: ast.Span{
+							Start: 4,
+							Stop:  4,
+							Type:  "Literal",
+						},
+						Value: 2,
+					},
+				},
+				Span: ast.Span{
+					Start: 0,
+					Stop:  5,
+					Type:  "TupleLiteral",
+				},
+			},
+		}, input: `(1, 2)`},
+		{name: "block if", want: []ast.Statement{
+			ast.IfBlock{
+				Test: ast.Identifier{
+					Span: ast.Span{
+						Start: 3,
+						Stop:  6,
+						Type:  "Identifier",
+					},
+					Value: "true",
+				},
+				Consequent: ast.BlockStatement{
+					Body: []ast.Statement{
+						ast.Literal{
+							Span: ast.Span{
+								Start: 11,
+								Stop:  11,
+								Type:  "Literal",
+							},
+							Value: 1,
+						},
+					},
+					Span: ast.Span{
+						Start: 11,
+						Stop:  11,
+						Type:  "BlockStatement",
+					},
+				},
+				Span: ast.Span{
+					Start: 0,
+					Stop:  15,
+					Type:  "IfExpression",
+				},
+			},
+		}, input: `if true do
+1
+end`},
 		{name: "function declaration", want: []ast.Statement{
 			ast.FunctionExpression{
 				Body: []ast.Statement{
 					ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 19,
-							End:   19,
+							Stop:  19,
 							Type:  "Identifier",
 						},
 						Value: "a",
 					},
 				},
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   23,
+					Stop:  23,
 					Type:  "FunctionExpression",
 				},
 				Name: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 3,
-						End:   7,
+						Stop:  7,
 						Type:  "Identifier",
 					},
 					Value: "hello",
@@ -530,17 +630,17 @@ func TestLiteralsAndExpressions(t *testing.T) {
 
 				Parameters: []ast.Identifier{
 					{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 10,
-							End:   10,
+							Stop:  10,
 							Type:  "Identifier",
 						},
 						Value: "a",
 					},
 					{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 13,
-							End:   13,
+							Stop:  13,
 							Type:  "Identifier",
 						},
 						Value: "b",
@@ -550,32 +650,32 @@ func TestLiteralsAndExpressions(t *testing.T) {
 		}, input: `fn hello |a, b| -> a end`},
 		{name: "function evaluation", want: []ast.Statement{
 			ast.FunctionEvaluation{
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   10,
+					Stop:  10,
 					Type:  "FunctionEvaluation",
 				},
 				Name: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 0,
-						End:   4,
+						Stop:  4,
 						Type:  "Identifier",
 					},
 					Value: "hello",
 				},
-				Arguments: []ast.Statement{
+				Arguments: []ast.Expression{
 					ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 6,
-							End:   6,
+							Stop:  6,
 							Type:  "Identifier",
 						},
 						Value: "a",
 					},
 					ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 9,
-							End:   9,
+							Stop:  9,
 							Type:  "Identifier",
 						},
 						Value: "b",
@@ -587,31 +687,31 @@ func TestLiteralsAndExpressions(t *testing.T) {
 			ast.FunctionExpression{
 				Body: []ast.Statement{
 					ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 19,
-							End:   19,
+							Stop:  19,
 							Type:  "Identifier",
 						},
 						Value: "a",
 					},
 					ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 21,
-							End:   21,
+							Stop:  21,
 							Type:  "Identifier",
 						},
 						Value: "b",
 					},
 				},
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   25,
+					Stop:  25,
 					Type:  "FunctionExpression",
 				},
 				Name: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 3,
-						End:   7,
+						Stop:  7,
 						Type:  "Identifier",
 					},
 					Value: "hello",
@@ -619,17 +719,17 @@ func TestLiteralsAndExpressions(t *testing.T) {
 
 				Parameters: []ast.Identifier{
 					{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 10,
-							End:   10,
+							Stop:  10,
 							Type:  "Identifier",
 						},
 						Value: "a",
 					},
 					{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 13,
-							End:   13,
+							Stop:  13,
 							Type:  "Identifier",
 						},
 						Value: "b",
@@ -644,9 +744,9 @@ end`},
 			ast.LetStatement{
 				Left: ast.Identifier{
 					Value: "incr",
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 4,
-						End:   7,
+						Stop:  7,
 						Type:  "Identifier",
 					},
 				},
@@ -654,24 +754,24 @@ end`},
 					Body: []ast.Statement{
 						ast.BinaryExpression{
 							Left: ast.Identifier{
-								Node: ast.Node{
+								Span: ast.Span{
 									Start: 21,
-									End:   21,
+									Stop:  21,
 									Type:  "Identifier",
 								},
 								Value: "a",
 							},
 							Right: ast.Literal{
 								Value: 1,
-								Node: ast.Node{
+								Span: ast.Span{
 									Start: 25,
-									End:   25,
+									Stop:  25,
 									Type:  "Literal",
 								},
 							},
-							Node: ast.Node{
+							Span: ast.Span{
 								Start: 21,
-								End:   25,
+								Stop:  25,
 								Type:  "BinaryExpression",
 							},
 							Operator: "+",
@@ -679,25 +779,25 @@ end`},
 					},
 					Parameters: []ast.Identifier{
 						{
-							Node: ast.Node{
+							Span: ast.Span{
 								Start: 15,
-								End:   15,
+								Stop:  15,
 								Type:  "Identifier",
 							},
 							Value: "a",
 						},
 					},
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 11,
-						End:   29,
+						Stop:  29,
 						Type:  "FunctionExpression",
 					},
 					Name: ast.Identifier{},
 				},
 				Operator: "=",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   29,
+					Stop:  29,
 					Type:  "LetStatement",
 				},
 			},
@@ -706,9 +806,9 @@ end`},
 			ast.LetStatement{
 				Left: ast.Identifier{
 					Value: "incr",
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 4,
-						End:   7,
+						Stop:  7,
 						Type:  "Identifier",
 					},
 				},
@@ -716,24 +816,24 @@ end`},
 					Body: []ast.Statement{
 						ast.BinaryExpression{
 							Left: ast.Identifier{
-								Node: ast.Node{
+								Span: ast.Span{
 									Start: 21,
-									End:   21,
+									Stop:  21,
 									Type:  "Identifier",
 								},
 								Value: "a",
 							},
 							Right: ast.Literal{
 								Value: 1,
-								Node: ast.Node{
+								Span: ast.Span{
 									Start: 25,
-									End:   25,
+									Stop:  25,
 									Type:  "Literal",
 								},
 							},
-							Node: ast.Node{
+							Span: ast.Span{
 								Start: 21,
-								End:   25,
+								Stop:  25,
 								Type:  "BinaryExpression",
 							},
 							Operator: "+",
@@ -741,25 +841,25 @@ end`},
 					},
 					Parameters: []ast.Identifier{
 						{
-							Node: ast.Node{
+							Span: ast.Span{
 								Start: 15,
-								End:   15,
+								Stop:  15,
 								Type:  "Identifier",
 							},
 							Value: "a",
 						},
 					},
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 11,
-						End:   29,
+						Stop:  29,
 						Type:  "FunctionExpression",
 					},
 					Name: ast.Identifier{},
 				},
 				Operator: "=",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   29,
+					Stop:  29,
 					Type:  "LetStatement",
 				},
 			},
@@ -769,25 +869,25 @@ end`},
 		{name: "logical AND", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Identifier",
 						Start: 0,
-						End:   3,
+						Stop:  3,
 					},
 					Value: "true",
 				},
 				Right: ast.Identifier{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Identifier",
 						Start: 9,
-						End:   13,
+						Stop:  13,
 					},
 					Value: "false",
 				},
 				Operator: "and",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   13,
+					Stop:  13,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -796,56 +896,56 @@ end`},
 			ast.BinaryExpression{
 				Left: ast.BinaryExpression{
 					Left: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 0,
-							End:   1,
+							Stop:  1,
 							Type:  "Literal",
 						},
 						Value: 10,
 					},
 					Right: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 5,
-							End:   6,
+							Stop:  6,
 							Type:  "Literal",
 						},
 						Value: 12,
 					},
 					Operator: "<",
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 0,
-						End:   6,
+						Stop:  6,
 						Type:  "BinaryExpression",
 					},
 				},
 				Right: ast.BinaryExpression{
 					Left: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 11,
-							End:   12,
+							Stop:  12,
 							Type:  "Literal",
 						},
 						Value: 14,
 					},
 					Right: ast.Literal{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 16,
-							End:   17,
+							Stop:  17,
 							Type:  "Literal",
 						},
 						Value: 34,
 					},
 					Operator: ">",
-					Node: ast.Node{
+					Span: ast.Span{
 						Start: 11,
-						End:   17,
+						Stop:  17,
 						Type:  "BinaryExpression",
 					},
 				},
 				Operator: "or",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   17,
+					Stop:  17,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -853,42 +953,42 @@ end`},
 		{name: "function evaluation (left side) in binary expression", want: []ast.Statement{
 			ast.BinaryExpression{
 				Left: ast.FunctionEvaluation{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "FunctionEvaluation",
 						Start: 0,
-						End:   6,
+						Stop:  6,
 					},
-					Arguments: []ast.Statement{
+					Arguments: []ast.Expression{
 						ast.Literal{
-							Node: ast.Node{
+							Span: ast.Span{
 								Start: 5,
-								End:   5,
+								Stop:  5,
 								Type:  "Literal",
 							},
 							Value: 1,
 						},
 					},
 					Name: ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 0,
-							End:   3,
+							Stop:  3,
 							Type:  "Identifier",
 						},
 						Value: "incr",
 					},
 				},
 				Right: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 10,
-						End:   11,
+						Stop:  11,
 					},
 					Value: 13,
 				},
 				Operator: "+",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   11,
+					Stop:  11,
 					Type:  "BinaryExpression",
 				},
 			},
@@ -896,46 +996,182 @@ end`},
 		{name: "function evaluation (right side) in binary expression", want: []ast.Statement{
 			ast.BinaryExpression{
 				Right: ast.FunctionEvaluation{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "FunctionEvaluation",
 						Start: 5,
-						End:   11,
+						Stop:  11,
 					},
-					Arguments: []ast.Statement{
+					Arguments: []ast.Expression{
 						ast.Literal{
-							Node: ast.Node{
+							Span: ast.Span{
 								Start: 10,
-								End:   10,
+								Stop:  10,
 								Type:  "Literal",
 							},
 							Value: 1,
 						},
 					},
 					Name: ast.Identifier{
-						Node: ast.Node{
+						Span: ast.Span{
 							Start: 5,
-							End:   8,
+							Stop:  8,
 							Type:  "Identifier",
 						},
 						Value: "incr",
 					},
 				},
 				Left: ast.Literal{
-					Node: ast.Node{
+					Span: ast.Span{
 						Type:  "Literal",
 						Start: 0,
-						End:   1,
+						Stop:  1,
 					},
 					Value: 13,
 				},
 				Operator: "+",
-				Node: ast.Node{
+				Span: ast.Span{
 					Start: 0,
-					End:   11,
+					Stop:  11,
 					Type:  "BinaryExpression",
 				},
 			},
 		}, input: "13 + incr(1)"},
+		{name: "range with step", want: []ast.Statement{
+			ast.RangeExpression{
+				From: ast.Literal{
+					Span: ast.Span{
+						Type:  "Literal",
+						Start: 0,
+						Stop:  0,
+					},
+					Value: 1,
+				},
+				To: ast.BinaryExpression{
+					Left: ast.Identifier{
+						Span: ast.Span{
+							Type:  "Identifier",
+							Start: 3,
+							Stop:  3,
+						},
+						Value: "n",
+					},
+					Right: ast.Literal{
+						Span: ast.Span{
+							Type:  "Literal",
+							Start: 7,
+							Stop:  7,
+						},
+						Value: 1,
+					},
+					Operator: "+",
+					Span: ast.Span{
+						Start: 3,
+						Stop:  7,
+						Type:  "BinaryExpression",
+					},
+				},
+				Step: ast.Literal{
+					Span: ast.Span{
+						Type:  "Literal",
+						Start: 12,
+						Stop:  12,
+					},
+					Value: 2,
+				},
+				Inclusive: true,
+				Span: ast.Span{
+					Start: 0,
+					Stop:  12,
+					Type:  "RangeExpression",
+				},
+			},
+		}, input: "1..n + 1 by 2"},
+		{name: "indexing a list literal", want: []ast.Statement{
+			ast.IndexExpression{
+				Left: ast.ListLiteral{
+					Elements: []ast.Expression{
+						ast.Literal{
+							Span: ast.Span{
+								Type:  "Literal",
+								Start: 1,
+								Stop:  1,
+							},
+							Value: 1,
+						},
+						ast.Literal{
+							Span: ast.Span{
+								Type:  "Literal",
+								Start: 4,
+								Stop:  4,
+							},
+							Value: 2,
+						},
+					},
+					Span: ast.Span{
+						Start: 0,
+						Stop:  5,
+						Type:  "ListLiteral",
+					},
+				},
+				Index: ast.Identifier{
+					Span: ast.Span{
+						Type:  "Identifier",
+						Start: 7,
+						Stop:  7,
+					},
+					Value: "i",
+				},
+				Span: ast.Span{
+					Start: 0,
+					Stop:  8,
+					Type:  "IndexExpression",
+				},
+			},
+		}, input: "[1, 2][i]"},
+		{name: "calling the result of a call", want: []ast.Statement{
+			ast.FunctionEvaluation{
+				Span: ast.Span{
+					Start: 0,
+					Stop:  6,
+					Type:  "FunctionEvaluation",
+				},
+				Callee: ast.FunctionEvaluation{
+					Span: ast.Span{
+						Start: 0,
+						Stop:  3,
+						Type:  "FunctionEvaluation",
+					},
+					Name: ast.Identifier{
+						Span: ast.Span{
+							Start: 0,
+							Stop:  0,
+							Type:  "Identifier",
+						},
+						Value: "f",
+					},
+					Arguments: []ast.Expression{
+						ast.Literal{
+							Span: ast.Span{
+								Start: 2,
+								Stop:  2,
+								Type:  "Literal",
+							},
+							Value: 1,
+						},
+					},
+				},
+				Arguments: []ast.Expression{
+					ast.Literal{
+						Span: ast.Span{
+							Start: 5,
+							Stop:  5,
+							Type:  "Literal",
+						},
+						Value: 2,
+					},
+				},
+			},
+		}, input: "f(1)(2)"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -947,10 +1183,251 @@ end`},
 			}
 			result := parser.Parse()
 			for i := range result.Body {
-				if !reflect.DeepEqual(result.Body[i], tt.want[i]) {
-					t.Errorf("got %+v, want %+v", result.Body[i], tt.want[i])
+				if want := located(tt.input, tt.want[i]); !reflect.DeepEqual(result.Body[i], want) {
+					t.Errorf("got %+v, want %+v", result.Body[i], want)
 				}
 			}
 		})
 	}
 }
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "reserved variable name", want: []string{"error: 'end' is a reserved word and cannot be used as a name at line: 1, column: 5"}, input: `let end = 1`},
+		{name: "reserved constant name", want: []string{"error: 'let' is a reserved word and cannot be used as a name at line: 1, column: 7"}, input: `const let = 1`},
+		{name: "reserved function name", want: []string{"error: 'do' is a reserved word and cannot be used as a name at line: 1, column: 4"}, input: `fn do |x| -> x end`},
+		{name: "reserved parameter name", want: []string{"error: 'if' is a reserved word and cannot be used as a name at line: 1, column: 10"}, input: `fn f |a, if| -> a end`},
+		{name: "reserved loop variable", want: []string{"error: 'in' is a reserved word and cannot be used as a name at line: 1, column: 5"}, input: `for in in [1] do 1 end`},
+		{name: "assignment to a reserved word", want: []string{"error: 'true' is a reserved word and cannot be used as a name at line: 2, column: 1"}, input: "let x = 1\ntrue = 3\nx = 2"},
+		{name: "missing operand", want: []string{"error: missing operand for '+' at line: 1, column: 4"}, input: "1 +"},
+		{name: "missing operator", want: []string{"error: missing operator before this expression at line: 1, column: 3"}, input: "a b"},
+		{name: "unexpected character", want: []string{"error: unexpected '$' at line: 1, column: 7"}, input: "x = 1 $ 2"},
+		{name: "empty right side", want: []string{"error: expected an expression, found end of line at line: 1, column: 8"}, input: "let x =\nx"},
+		{name: "stray end", want: []string{"error: expected an expression, found 'end' at line: 2, column: 1"}, input: "1\nend"},
+		{name: "unclosed call", want: []string{"error: missing ')' in function call at line: 2, column: 2"}, input: "print(1,\n2\nlet x = 1"},
+		{name: "unclosed list", want: []string{"error: missing ']' in list literal at line: 1, column: 6"}, input: "[1, 2\nx = 3"},
+		{name: "missing function bars", want: []string{
+			"error: missing '|' before function parameters at line: 1, column: 6",
+			"error: missing '|' after function parameters at line: 2, column: 12",
+		}, input: "fn f -> 1 end\nfn g |a, b -> a end"},
+		{name: "missing arrow", want: []string{"error: missing '->' before function body at line: 1, column: 10"}, input: "fn f |a| a end"},
+		{name: "missing do", want: []string{
+			"error: missing 'do' symbol at line: 1, column: 12",
+			"error: missing operand for '*' at line: 2, column: 10",
+		}, input: "while x < 3\n  x = x *\nend"},
+		{name: "missing loop variable", want: []string{"error: missing loop variable after 'for' at line: 1, column: 5"}, input: "for 1 in xs do\n  x\nend\ny"},
+		{name: "errors in every branch", want: []string{
+			"error: missing operand for '-' at line: 2, column: 6",
+			"error: missing operator before this expression at line: 4, column: 5",
+			"error: missing operand for '/' at line: 6, column: 6",
+		}, input: "if a do\n  b -\nelif c do\n  d e\nelse\n  f /\nend"},
+		{name: "unterminated if block", want: []string{"error: missing 'end' at line: 3, column: 1"}, input: "if c do\n  x\n"},
+		{name: "unterminated else block", want: []string{"error: missing 'end' at line: 4, column: 4"}, input: "if c do\n  x\nelse\n  y"},
+		{name: "inline branches", want: []string{
+			"error: missing operand for '+' at line: 1, column: 13",
+			"error: unbalanced parenthesis at line: 1, column: 19",
+		}, input: "if a do 1 + else 2)\nb"},
+		{name: "inline elif after an error", want: []string{
+			"error: missing operand for '+' at line: 1, column: 13",
+			"error: unbalanced parenthesis at line: 1, column: 31",
+		}, input: "if a do 1 + elif b do 2 else 3)\nb"},
+		{name: "operand is a reserved word", want: []string{"error: missing operand for '*' at line: 1, column: 9"}, input: "a = 2 * do"},
+		{name: "missing operand for unary minus", want: []string{"error: missing operand for '-' at line: 1, column: 6"}, input: "[1, -]"},
+		{name: "range step without a range", want: []string{"error: 'by' must follow a range at line: 1, column: 3"}, input: "1 by 2"},
+		{name: "unbalanced parenthesis", want: []string{"error: unbalanced parenthesis at line: 1, column: 17"}, input: "if a do 1 else 2)\nb"},
+		{name: "bad match clauses", want: []string{
+			"error: expected an expression, found end of line at line: 2, column: 7",
+			"error: missing '->' in match clause at line: 3, column: 5",
+		}, input: "match x do\n  1 ->\n  2 => 3\n  _ -> 0\nend"},
+		{name: "every statement", want: []string{
+			"error: missing operand for '+' at line: 1, column: 12",
+			"error: unbalanced parenthesis at line: 2, column: 15",
+			"error: missing ':' after map key at line: 3, column: 13",
+			"error: missing index at line: 4, column: 4",
+		}, input: "let a = 1 +\nlet b = (2 * 3\nlet c = {\"k\", 1}\nxs[]\nprint(a)"},
+		{name: "syntax errors after a lexical error", want: []string{
+			"error: invalid escape sequence '\\q' at line: 1, column: 11",
+			"error: missing operand for '+' at line: 2, column: 12",
+			"error: unbalanced parenthesis at line: 3, column: 15",
+			"error: missing index at line: 4, column: 4",
+		}, input: "let x = \"a\\q\"\nlet a = 1 +\nlet b = (2 * 3\nxs[]"},
+		{name: "syntax error after a lexical error on the same line", want: []string{
+			"error: invalid digit 'z' in hexadecimal literal at line: 1, column: 11",
+			"error: missing operand for '*' at line: 1, column: 15",
+		}, input: "let x = 0xz * \ny"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := New(lexer.New([]rune(tt.input)))
+			parser.Parse()
+			var got []string
+			for _, err := range parser.Errors {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// located returns a copy of the tree stmt in which every node is positioned
+// at its Start offset in input.
+func located(input string, stmt ast.Statement) ast.Statement {
+	if stmt == nil {
+		return nil
+	}
+	return locate(input, reflect.ValueOf(stmt)).Interface().(ast.Statement)
+}
+
+func locate(input string, v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(locate(input, v.Elem()))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(locate(input, v.Index(i)))
+		}
+		return copied
+	case reflect.Pointer:
+		if v.IsNil() || v.Type().Elem().PkgPath() != reflect.TypeOf(ast.Span{}).PkgPath() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(locate(input, v.Elem()))
+		return copied
+	case reflect.Struct:
+		if v.Type().PkgPath() != reflect.TypeOf(ast.Span{}).PkgPath() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		if span, ok := v.Interface().(ast.Span); ok {
+			if span.Type != "" {
+				span.Position = position(input, span.Start)
+			}
+			copied.Set(reflect.ValueOf(span))
+			return copied
+		}
+		for i := 0; i < v.NumField(); i++ {
+			copied.Field(i).Set(locate(input, v.Field(i)))
+		}
+		return copied
+	}
+	return v
+}
+
+// position locates offset in input by counting the lines before it.
+func position(input string, offset int) token.Position {
+	runes := []rune(input)
+	line, lineStart := 1, 0
+	for i := 0; i < offset && i < len(runes); i++ {
+		if runes[i] == '\n' {
+			line, lineStart = line+1, i+1
+		}
+	}
+	return token.Position{Line: line, Column: offset - lineStart + 1, Offset: offset}
+}
+
+func TestPositions(t *testing.T) {
+	input := "let total = 0\r\nfor größe in xs do\n\ttotal = total + \"\"\"\n#{\n  größe * 2}\"\"\"\nend"
+	parser := New(lexer.NewFile("sum.om", []rune(input)))
+	program := parser.Parse()
+	if len(parser.Errors) > 0 {
+		t.Fatal(parser.Errors)
+	}
+	loop := program.Body[1].(ast.ForExpression)
+	assignment := loop.Body[0].(ast.AssignmentStatement)
+	sum := assignment.Right.(ast.BinaryExpression)
+	interpolated := sum.Right.(ast.InterpolatedString)
+	product := interpolated.Parts[0].(ast.BinaryExpression)
+	tests := []struct {
+		name string
+		got  token.Position
+		want string
+	}{
+		{name: "let statement", got: program.Body[0].(ast.LetStatement).Pos(), want: "sum.om:1:1"},
+		{name: "after a crlf", got: loop.Pos(), want: "sum.om:2:1"},
+		{name: "after a multi-byte identifier", got: loop.Iterable.(ast.Identifier).Pos(), want: "sum.om:2:14"},
+		{name: "after a tab", got: assignment.Pos(), want: "sum.om:3:2"},
+		{name: "binary expression", got: sum.Pos(), want: "sum.om:3:10"},
+		{name: "interpolated string", got: interpolated.Pos(), want: "sum.om:3:18"},
+		{name: "embedded expression", got: product.Pos(), want: "sum.om:5:3"},
+		{name: "embedded operand", got: product.Right.(ast.Literal).Pos(), want: "sum.om:5:11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.String() != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"let a = 1 + 2 * 3\n",
+		"fn add |a, b| -> a + b end\nadd(1, 2)\n",
+		"if a < 1 do\n  b\nelif c do\n  d\nelse\n  e\nend\n",
+		"for i in 1..10 by 2 do total = total + i end\n",
+		"while i < 3 do i = i + 1 end\n",
+		"match p do\n  (0, y) if y > 0 -> y\n  _ -> 0\nend\n",
+		`xs = [1, (2,), {"k": -x}][0][1:]` + "\n",
+		`"sum: #{a + b}"` + "\n",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		New(lexer.New([]rune(input))).Parse()
+	})
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "precedence", input: "let a = (1 + 2) * -x ** 2", want: "let a = (1 + 2) * (-(x ** 2))"},
+		{name: "unary", input: "const b = -(a + 1)", want: "const b = -(a + 1)"},
+		{name: "numbers", input: "[1, 2.0, 1.5e3, 0.1d, 99999999999999999999]", want: "[1, 2.0, 1500.0, 0.1d, 99999999999999999999]"},
+		{name: "collections", input: "xs[0] = {\"k\": (1,), \"v\": (1, 2)}[\"k\"][1:]", want: "xs[0] = {\"k\": (1,), \"v\": (1, 2)}[\"k\"][1:]"},
+		{name: "range", input: "for i in 1..<10 by 2 do print(i) end", want: "for i in 1 ..< 10 by 2 do\n  print(i)\nend"},
+		{name: "function", input: "fn add |a, b| -> return a + b end", want: "fn add |a, b| ->\n  return a + b\nend"},
+		{name: "call on a call", input: "make()(1)", want: "make()(1)"},
+		{name: "inline if", input: "if a do 1 else 2", want: "if a do 1 else 2"},
+		{name: "if chain", input: "if a do\n1\nelif b do\n2\nelse\n3\nend", want: "if a do\n  1\nelif b do\n  2\nelse\n  3\nend"},
+		{name: "while", input: "while i < 3 do\nif i == 1 do\nbreak\nend\ni = i + 1\nend", want: "while i < 3 do\n  if i == 1 do\n    break\n  end\n  i = i + 1\nend"},
+		{name: "match", input: "match p do\n(0, y) if y > 0 -> y\n_ -> 0\nend", want: "match p do\n  (0, y) if y > 0 -> y\n  _ -> 0\nend"},
+		{name: "interpolation", input: `"a\t#{x + 1}\"\#{"`, want: `"a\t#{x + 1}\"\#{"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := New(lexer.New([]rune(tt.input)))
+			got := parser.Parse().String()
+			if len(parser.Errors) > 0 {
+				t.Fatal(parser.Errors)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			reparser := New(lexer.New([]rune(got)))
+			if again := reparser.Parse().String(); again != got || len(reparser.Errors) > 0 {
+				t.Errorf("reparsing %q gave %q, %v", got, again, reparser.Errors)
+			}
+		})
+	}
+}
@@ -12,16 +12,21 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
 	"github.com/iamBharatManral/atom.git/cmd/internal/env"
 	"github.com/iamBharatManral/atom.git/cmd/internal/interpreter"
 	"github.com/iamBharatManral/atom.git/cmd/internal/lexer"
 	"github.com/iamBharatManral/atom.git/cmd/internal/parser"
+	"github.com/iamBharatManral/atom.git/cmd/internal/token"
 	"github.com/iamBharatManral/atom.git/cmd/internal/util"
+	"github.com/iamBharatManral/atom.git/cmd/internal/value"
 )
 
 const MAIN_PROMPT = "λ> "
 const REST_OF_LINE_PROMPT = "... "
 
+var scanner = bufio.NewScanner(os.Stdin)
+
 func Start() {
 	util.Banner()
 	message()
@@ -42,40 +47,51 @@ func Start() {
 	userInputLoop()
 }
 
+// sources holds every input read so far by the name its positions refer
+// to, as an error can come from a function defined by an earlier input.
+var sources = map[string][]rune{}
+
 func userInputLoop() {
 	env := env.New()
-	for {
+	for n := 1; ; n++ {
 		input := userInput()
-		lexer := lexer.New(input)
+		name := fmt.Sprintf("<repl-%d>", n)
+		sources[name] = input
+		lexer := lexer.NewFile(name, input)
 		parser := parser.New(lexer)
 		program := parser.Parse()
 		if len(parser.Errors) > 0 {
 			for _, err := range parser.Errors {
-				fmt.Println(err)
+				report(err)
 			}
 			continue
 		}
 		for _, stmt := range program.Body {
-			result := interpreter.Eval(stmt, env)
-			if result.Type == "error" {
-				fmt.Println(result.Value)
+			result, err := interpreter.Eval(stmt, env)
+			if err != nil {
+				fmt.Print(err.Render(source, diagnostic.Colorful(os.Stdout)))
 				continue
-			} else if result.Type == "" || result.Value == "()" {
+			} else if _, ok := result.(value.Nil); ok {
 				fmt.Println()
 				continue
 			}
-			fmt.Printf("%v\n", result.Value)
-
+			fmt.Println(result)
 		}
 	}
 }
 
+// report prints an error with the line of input it points at.
+func report(d diagnostic.Diagnostic) {
+	fmt.Print(d.Render(source(d.Pos.File), diagnostic.Colorful(os.Stdout)))
+}
+
+// source returns the input that positions in the named file refer to.
+func source(name string) []rune {
+	return sources[name]
+}
+
 func userInput() []rune {
-	endOfStatements := make(map[string]string)
-	endOfStatements["fn"] = "end"
 	var finalInput string
-	scanner := bufio.NewScanner(os.Stdin)
-	var endOfStatement string = endOfStatements["let"]
 	var lineContinuation bool
 	for {
 		if !lineContinuation {
@@ -83,19 +99,14 @@ func userInput() []rune {
 		} else {
 			fmt.Print(REST_OF_LINE_PROMPT)
 		}
-		scanner.Scan()
-		if err := scanner.Err(); err != nil {
-			log.Fatal(err)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println()
+			os.Exit(0)
 		}
 		input := scanner.Text()
-		finalInput += input + "\n"
-		if strings.Contains(finalInput, "fn") {
-			endOfStatement = endOfStatements["fn"]
-		}
-
-		if len(input) == 0 {
-			continue
-		}
 		if input == ":q" || input == ":quit" {
 			os.Exit(0)
 		}
@@ -103,16 +114,57 @@ func userInput() []rune {
 			clearTerminal()
 			continue
 		}
-		if strings.Contains(input, endOfStatement) {
-			break
-		} else {
+		finalInput += input + "\n"
+		if openBlocks(finalInput) > 0 {
 			lineContinuation = true
+			continue
 		}
-
+		break
 	}
 	return []rune(finalInput)
 
 }
+
+// openBlocks counts the blocks and brackets in input that are still
+// waiting to be closed.
+func openBlocks(input string) int {
+	if strings.Count(input, `"""`)%2 == 1 {
+		return 1
+	}
+	lexer := lexer.New([]rune(input))
+	depth := 0
+	// an if only opens a block when its 'do' ends the line; 'else if' and
+	// 'elif' continue the block of the enclosing if
+	pendingIf := false
+	previous := ""
+	for tok := lexer.NextToken(); tok.TokenType() != token.EOF; tok = lexer.NextToken() {
+		switch tok.TokenType() {
+		case token.NEWLINE:
+			pendingIf = false
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			depth--
+		}
+		switch tok.Lexeme() {
+		case "fn", "while", "for", "match":
+			depth++
+		case "end":
+			depth--
+		case "if":
+			pendingIf = previous != "else"
+		case "do":
+			next := lexer.PeekToken(1).TokenType()
+			if pendingIf && (next == token.NEWLINE || next == token.EOF) {
+				depth++
+			}
+			pendingIf = false
+		}
+		previous = tok.Lexeme()
+	}
+	return depth
+}
+
 func message() {
 	var username string
 	currentUser, err := user.Current()
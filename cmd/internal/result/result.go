@@ -1,6 +0,0 @@
-package result
-
-type Result struct {
-	Value any
-	Type  string
-}
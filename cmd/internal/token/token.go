@@ -1,19 +1,29 @@
 package token
 
+import "fmt"
+
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 	NEWLINE = "NEWLINE"
 
-	PLUS  = "PLUS"
-	MINUS = "MINUS"
-	STAR  = "STAR"
-	SLASH = "SLASH"
-	MOD   = "MOD"
+	// INVALID tokens are malformed literals the lexer has already reported
+	// an error for.
+	INVALID = "INVALID"
+
+	PLUS       = "PLUS"
+	MINUS      = "MINUS"
+	STAR       = "STAR"
+	STARSTAR   = "STARSTAR"
+	SLASH      = "SLASH"
+	SLASHSLASH = "SLASHSLASH"
+	MOD        = "MOD"
 
-	STRING  = "STRING"
-	INTEGER = "INTEGER"
-	FLOAT   = "FLOAT"
+	STRING       = "STRING"
+	INTERPOLATED = "INTERPOLATED"
+	INTEGER      = "INTEGER"
+	FLOAT        = "FLOAT"
+	DECIMAL      = "DECIMAL"
 
 	LE     = "LE"
 	EQ     = "EQ"
@@ -25,83 +35,168 @@ const (
 
 	NOT = "NOT"
 
+	DOTDOT   = "DOTDOT"
+	DOTDOTLT = "DOTDOTLT"
+
 	ARROW  = "ARROW"
 	BAR    = "BAR"
 	COMMA  = "COMMA"
 	LPAREN = "LPAREN"
 	RPAREN = "RPAREN"
 
+	LBRACKET = "LBRACKET"
+	RBRACKET = "RBRACKET"
+	LBRACE   = "LBRACE"
+	RBRACE   = "RBRACE"
+	COLON    = "COLON"
+
 	IDENTIFIER = "IDENTIFIER"
+
+	// COMMENT tokens are trivia: the lexer keeps them aside instead of
+	// handing them to the parser.
+	COMMENT = "COMMENT"
 )
 
 var keywords = make(map[string]string)
-var priorities = make(map[string][]any)
-
-func RegisterPriorities() {
-	priorities["NONE"] = []any{0, "left"}
-	priorities["or"] = []any{1, "left"}
-	priorities["and"] = []any{2, "left"}
-
-	priorities["=="] = []any{3, "left"}
-	priorities["!="] = []any{3, "left"}
 
-	priorities["<="] = []any{4, "left"}
-	priorities["<"] = []any{4, "left"}
-	priorities[">"] = []any{4, "left"}
-	priorities[">="] = []any{4, "left"}
+// Precedence is how tightly an operator binds its operands. An operator of
+// a higher precedence binds tighter.
+type Precedence int
 
-	priorities["+"] = []any{5, "left"}
-	priorities["-"] = []any{5, "left"}
-
-	priorities["*"] = []any{6, "left"}
-	priorities["/"] = []any{6, "left"}
-	priorities["%"] = []any{6, "left"}
+const (
+	LOWEST     Precedence = iota
+	OR                    // or
+	AND                   // and
+	EQUALITY              // == !=
+	COMPARISON            // < <= > >= in
+	STEP                  // by
+	RANGE                 // .. ..<
+	SUM                   // + -
+	PRODUCT               // * / // %
+	PREFIX                // -x !x
+	POWER                 // **
+	POSTFIX               // f(x) xs[i]
+)
 
-	priorities["!"] = []any{7, "left"}
+var precedences = map[string]Precedence{
+	EQ:         EQUALITY,
+	NE:         EQUALITY,
+	LT:         COMPARISON,
+	LE:         COMPARISON,
+	GT:         COMPARISON,
+	GE:         COMPARISON,
+	DOTDOT:     RANGE,
+	DOTDOTLT:   RANGE,
+	PLUS:       SUM,
+	MINUS:      SUM,
+	STAR:       PRODUCT,
+	SLASH:      PRODUCT,
+	SLASHSLASH: PRODUCT,
+	MOD:        PRODUCT,
+	STARSTAR:   POWER,
+	LPAREN:     POSTFIX,
+	LBRACKET:   POSTFIX,
+}
 
+var keywordPrecedences = map[string]Precedence{
+	"or":  OR,
+	"and": AND,
+	"in":  COMPARISON,
+	"by":  STEP,
 }
 
-func GetPriority(token string) []any {
-	if priority, ok := priorities[token]; ok {
-		return priority
+// Precedence returns the precedence of the token as an operator following
+// an operand, or LOWEST when it does not continue an expression.
+func (t Token) Precedence() Precedence {
+	if t.tokenType == IDENTIFIER {
+		return keywordPrecedences[t.lexeme]
 	}
-	return priorities["NONE"]
-
+	return precedences[t.tokenType]
 }
+
 func RegisterKeyWords() {
 	keywords["let"] = "let"
+	keywords["const"] = "const"
 	keywords["if"] = "if"
 	keywords["do"] = "do"
 	keywords["else"] = "else"
+	keywords["elif"] = "elif"
 	keywords["false"] = "false"
 	keywords["true"] = "true"
 	keywords["fn"] = "fn"
 	keywords["return"] = "return"
+	keywords["end"] = "end"
+	keywords["while"] = "while"
+	keywords["break"] = "break"
+	keywords["continue"] = "continue"
+	keywords["for"] = "for"
+	keywords["in"] = "in"
+	keywords["by"] = "by"
+	keywords["match"] = "match"
 }
 
+// IsKeyword reports whether key is a reserved word that cannot be used as a
+// name.
 func IsKeyword(key string) bool {
-	return keywords[key] == key
+	_, ok := keywords[key]
+	return ok
 }
 
 func GetKeyword(key string) string {
 	return keywords[key]
 }
 
+// StringPart is a piece of an interpolated string literal: either plain
+// text with its escape sequences decoded or the source of an expression
+// embedded with '#{...}'. Offset and End locate the part in the input.
+type StringPart struct {
+	Text       string
+	Offset     int
+	End        int
+	Expression bool
+}
+
+// Position is a location in the source. Offset is the 0-based index of a
+// rune in the input. Line and Column are 1-based and Column counts runes,
+// so a tab or a multi-byte character takes a single column. Lines end at
+// '\n', which also covers "\r\n".
+type Position struct {
+	File   string
+	Line   int
+	Column int
+	Offset int
+}
+
+// IsValid reports whether the position has been set.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String formats the position as file:line:column, leaving out the file
+// when there is none.
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
 type Token struct {
 	literal   any
 	lexeme    string
 	tokenType string
 	start     int
 	end       int
+	pos       Position
 }
 
 func New(tokenType string, lexeme string, literal any, start int, end int) Token {
 	return Token{
-		literal,
-		lexeme,
-		tokenType,
-		start,
-		end,
+		literal:   literal,
+		lexeme:    lexeme,
+		tokenType: tokenType,
+		start:     start,
+		end:       end,
 	}
 }
 
@@ -124,3 +219,14 @@ func (t Token) Start() int {
 func (t Token) End() int {
 	return t.end
 }
+
+// Pos returns the position of the first character of the token.
+func (t Token) Pos() Position {
+	return t.pos
+}
+
+// WithPos returns a copy of the token located at pos.
+func (t Token) WithPos(pos Position) Token {
+	t.pos = pos
+	return t
+}
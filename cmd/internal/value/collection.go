@@ -0,0 +1,243 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Range is an arithmetic progression of integers produced by the '..' and
+// '..<' operators.
+type Range struct {
+	From      int
+	To        int
+	Step      int
+	Inclusive bool
+}
+
+func (Range) Kind() Kind { return RangeKind }
+
+func (r Range) Equal(other Value) bool {
+	o, ok := other.(Range)
+	return ok && r == o
+}
+
+// Len returns the number of integers in the range. It reports false when
+// there are too many of them to count with an int.
+func (r Range) Len() (int, bool) {
+	span, step, ok := r.span()
+	if !ok {
+		return 0, true
+	}
+	n := span / step
+	if n >= math.MaxInt {
+		return 0, false
+	}
+	return int(n) + 1, true
+}
+
+// Each calls yield with the integers of the range in order until it
+// returns false.
+func (r Range) Each(yield func(int) bool) {
+	span, step, ok := r.span()
+	if !ok {
+		return
+	}
+	x := r.From
+	for i, n := uint64(0), span/step; yield(x) && i < n; i++ {
+		x += r.Step
+	}
+}
+
+func (r Range) Contains(value int) bool {
+	span, step, ok := r.span()
+	if !ok {
+		return false
+	}
+	var offset uint64
+	if r.Step > 0 {
+		if value < r.From {
+			return false
+		}
+		offset = uint64(value) - uint64(r.From)
+	} else {
+		if value > r.From {
+			return false
+		}
+		offset = uint64(r.From) - uint64(value)
+	}
+	return offset <= span && offset%step == 0
+}
+
+// span returns the distance from the first integer of the range to the
+// last bound it can reach, and the size of its step, both as unsigned
+// integers so that neither overflows for the widest ranges. It reports
+// false when the range is empty.
+func (r Range) span() (uint64, uint64, bool) {
+	last := r.To
+	if !r.Inclusive {
+		switch {
+		case r.To == r.From:
+			return 0, 0, false
+		case r.To > r.From:
+			last--
+		default:
+			last++
+		}
+	}
+	if r.Step > 0 {
+		if last < r.From {
+			return 0, 0, false
+		}
+		return uint64(last) - uint64(r.From), uint64(r.Step), true
+	}
+	if last > r.From {
+		return 0, 0, false
+	}
+	return uint64(r.From) - uint64(last), -uint64(r.Step), true
+}
+
+func (r Range) String() string {
+	op := ".."
+	if !r.Inclusive {
+		op = "..<"
+	}
+	if r.Step == 1 || (r.Step == -1 && r.From > r.To) {
+		return fmt.Sprintf("%d%s%d", r.From, op, r.To)
+	}
+	return fmt.Sprintf("%d%s%d by %d", r.From, op, r.To, r.Step)
+}
+
+// List is a mutable, ordered sequence of values.
+type List struct {
+	Elements []Value
+}
+
+func (*List) Kind() Kind { return ListKind }
+
+func (l *List) Equal(other Value) bool {
+	o, ok := other.(*List)
+	return ok && equalElements(l.Elements, o.Elements)
+}
+
+func (l *List) String() string {
+	return "[" + inspectElements(l.Elements) + "]"
+}
+
+// Tuple is an immutable, fixed-size sequence of values.
+type Tuple struct {
+	Elements []Value
+}
+
+func (Tuple) Kind() Kind { return TupleKind }
+
+func (t Tuple) Equal(other Value) bool {
+	o, ok := other.(Tuple)
+	return ok && equalElements(t.Elements, o.Elements)
+}
+
+func (t Tuple) String() string {
+	if len(t.Elements) == 1 {
+		return "(" + Inspect(t.Elements[0]) + ",)"
+	}
+	return "(" + inspectElements(t.Elements) + ")"
+}
+
+func equalElements(left, right []Value) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if !left[i].Equal(right[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func inspectElements(elements []Value) string {
+	items := make([]string, len(elements))
+	for i, element := range elements {
+		items[i] = Inspect(element)
+	}
+	return strings.Join(items, ", ")
+}
+
+// Map is a mutable hash map that remembers the order in which its keys
+// were first inserted.
+type Map struct {
+	keys    []Hashable
+	entries map[Key]Value
+}
+
+func NewMap() *Map {
+	return &Map{entries: make(map[Key]Value)}
+}
+
+func (*Map) Kind() Kind { return MapKind }
+
+func (m *Map) Get(key Hashable) (Value, bool) {
+	value, ok := m.entries[key.Hash()]
+	return value, ok
+}
+
+// Set binds key to value. A key equal to one already in the map replaces
+// its value but keeps its place and the key it was first inserted with.
+func (m *Map) Set(key Hashable, value Value) {
+	hash := key.Hash()
+	if _, ok := m.entries[hash]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.entries[hash] = value
+}
+
+// Delete removes key from the map and reports whether it was present.
+func (m *Map) Delete(key Hashable) bool {
+	hash := key.Hash()
+	if _, ok := m.entries[hash]; !ok {
+		return false
+	}
+	delete(m.entries, hash)
+	for i, k := range m.keys {
+		if k.Hash() == hash {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Keys returns the keys of the map in insertion order.
+func (m *Map) Keys() []Value {
+	keys := make([]Value, len(m.keys))
+	for i, key := range m.keys {
+		keys[i] = key
+	}
+	return keys
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+func (m *Map) Equal(other Value) bool {
+	o, ok := other.(*Map)
+	if !ok || m.Len() != o.Len() {
+		return false
+	}
+	for _, key := range m.keys {
+		value, ok := o.Get(key)
+		if !ok || !m.entries[key.Hash()].Equal(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Map) String() string {
+	items := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		items[i] = Inspect(key) + ": " + Inspect(m.entries[key.Hash()])
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
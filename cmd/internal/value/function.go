@@ -0,0 +1,54 @@
+package value
+
+import (
+	"fmt"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/ast"
+)
+
+// Scope is the environment a function was defined in. It is implemented
+// by *env.Environment, which cannot be named here since environments hold
+// values themselves.
+type Scope interface {
+	Get(symbol string) (Value, bool)
+}
+
+// Function is a function value together with the environment it was
+// defined in, so its body can see the bindings that were in scope there.
+type Function struct {
+	Declaration ast.FunctionExpression
+	Env         Scope
+}
+
+func (Function) Kind() Kind { return FunctionKind }
+
+func (f Function) Equal(other Value) bool {
+	o, ok := other.(Function)
+	return ok && f.Env == o.Env && f.Declaration.Start == o.Declaration.Start
+}
+
+func (f Function) String() string {
+	if f.Declaration.Name.Value == "" {
+		return "<fn>"
+	}
+	return fmt.Sprintf("<fn %s>", f.Declaration.Name.Value)
+}
+
+// Builtin is a function implemented by the interpreter itself. An Arity of
+// -1 accepts any number of arguments.
+type Builtin struct {
+	Name  string
+	Arity int
+	Fn    func(args []Value) (Value, *Error)
+}
+
+func (Builtin) Kind() Kind { return BuiltinKind }
+
+func (b Builtin) Equal(other Value) bool {
+	o, ok := other.(Builtin)
+	return ok && b.Name == o.Name
+}
+
+func (b Builtin) String() string {
+	return fmt.Sprintf("<builtin %s>", b.Name)
+}
@@ -0,0 +1,228 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+)
+
+// Numbers are Ints, BigInts, Floats or Decimals. Integers that do not fit
+// in an int are BigInts and are demoted back to Ints whenever they fit
+// again, so a BigInt is always outside the range of int. When numbers of
+// different kinds meet, the integers are converted. Decimals and floats
+// never mix, since that would lose the exactness decimals are used for.
+
+type Int int
+
+// BigInt is an integer outside the range of int.
+type BigInt struct {
+	*big.Int
+}
+
+type Float float64
+
+// Decimal is an exact base-10 number.
+type Decimal struct {
+	decimal.Decimal
+}
+
+func (Int) Kind() Kind     { return IntKind }
+func (BigInt) Kind() Kind  { return IntKind }
+func (Float) Kind() Kind   { return FloatKind }
+func (Decimal) Kind() Kind { return DecimalKind }
+
+func (n Int) String() string   { return strconv.Itoa(int(n)) }
+func (f Float) String() string { return fmt.Sprint(float64(f)) }
+
+func (n Int) Equal(other Value) bool     { return equalNumbers(n, other) }
+func (n BigInt) Equal(other Value) bool  { return equalNumbers(n, other) }
+func (f Float) Equal(other Value) bool   { return equalNumbers(f, other) }
+func (d Decimal) Equal(other Value) bool { return equalNumbers(d, other) }
+
+func (n Int) Compare(other Value) (int, bool)     { return compareNumbers(n, other) }
+func (n BigInt) Compare(other Value) (int, bool)  { return compareNumbers(n, other) }
+func (f Float) Compare(other Value) (int, bool)   { return compareNumbers(f, other) }
+func (d Decimal) Compare(other Value) (int, bool) { return compareNumbers(d, other) }
+
+func (n Int) Hash() Key {
+	return Key{kind: IntKind, bits: uint64(n)}
+}
+
+func (n BigInt) Hash() Key {
+	return Key{kind: IntKind, text: n.String()}
+}
+
+// Hash hashes a whole float as the integer it exactly equals, so
+// 9007199254740992.0 and 9007199254740993 get different keys.
+func (f Float) Hash() Key {
+	if !math.IsInf(float64(f), 0) && float64(f) == math.Trunc(float64(f)) {
+		n, _ := big.NewFloat(float64(f)).Int(nil)
+		return NormalizeInt(n).(Hashable).Hash()
+	}
+	return Key{kind: FloatKind, bits: math.Float64bits(float64(f))}
+}
+
+// Hash hashes a whole decimal as the integer it equals. Other decimals are
+// hashed without their trailing zeros, since 2.50 equals 2.5.
+func (d Decimal) Hash() Key {
+	n := d.Int()
+	if d.Cmp(decimal.FromInt(n)) == 0 {
+		return NormalizeInt(n).(Hashable).Hash()
+	}
+	return Key{kind: DecimalKind, text: strings.TrimRight(d.String(), "0")}
+}
+
+func IsNumber(v Value) bool {
+	switch v.(type) {
+	case Int, BigInt, Float, Decimal:
+		return true
+	}
+	return false
+}
+
+// NormalizeInt returns n as an Int when it fits in one and as a BigInt
+// otherwise.
+func NormalizeInt(n *big.Int) Value {
+	if n.IsInt64() && n.Int64() >= math.MinInt && n.Int64() <= math.MaxInt {
+		return Int(n.Int64())
+	}
+	return BigInt{n}
+}
+
+// ToBigInt converts an Int or a BigInt to a *big.Int.
+func ToBigInt(v Value) *big.Int {
+	if n, ok := v.(BigInt); ok {
+		return n.Int
+	}
+	return big.NewInt(int64(v.(Int)))
+}
+
+// ToFloat converts a number to a float64.
+func ToFloat(v Value) float64 {
+	switch v := v.(type) {
+	case Int:
+		return float64(v)
+	case BigInt:
+		f, _ := new(big.Float).SetInt(v.Int).Float64()
+		return f
+	case Decimal:
+		return v.Float64()
+	}
+	return float64(v.(Float))
+}
+
+// ToDecimal converts an integer or a Decimal to a Decimal.
+func ToDecimal(v Value) Decimal {
+	if d, ok := v.(Decimal); ok {
+		return d
+	}
+	return Decimal{decimal.FromInt(ToBigInt(v))}
+}
+
+// Promote converts two numbers to a common kind: both Ints, both BigInts,
+// both Floats or both Decimals. It reports false when either of them is
+// not a number or when a decimal meets a float.
+func Promote(left, right Value) (Value, Value, bool) {
+	if !IsNumber(left) || !IsNumber(right) {
+		return nil, nil, false
+	}
+	_, leftFloat := left.(Float)
+	_, rightFloat := right.(Float)
+	_, leftDecimal := left.(Decimal)
+	_, rightDecimal := right.(Decimal)
+	if leftDecimal || rightDecimal {
+		if leftFloat || rightFloat {
+			return nil, nil, false
+		}
+		return ToDecimal(left), ToDecimal(right), true
+	}
+	if leftFloat || rightFloat {
+		return Float(ToFloat(left)), Float(ToFloat(right)), true
+	}
+	_, leftInt := left.(Int)
+	_, rightInt := right.(Int)
+	if leftInt && rightInt {
+		return left, right, true
+	}
+	return BigInt{ToBigInt(left)}, BigInt{ToBigInt(right)}, true
+}
+
+func equalNumbers(left, right Value) bool {
+	c, ok := compareNumbers(left, right)
+	return ok && c == 0
+}
+
+func compareNumbers(left, right Value) (int, bool) {
+	if mixesIntAndFloat(left, right) {
+		return compareExactly(left, right)
+	}
+	l, r, ok := Promote(left, right)
+	if !ok {
+		return 0, false
+	}
+	switch l := l.(type) {
+	case Int:
+		return compare(l, r.(Int)), true
+	case BigInt:
+		return l.Cmp(r.(BigInt).Int), true
+	case Decimal:
+		return l.Cmp(r.(Decimal).Decimal), true
+	}
+	a, b := l.(Float), r.(Float)
+	if math.IsNaN(float64(a)) || math.IsNaN(float64(b)) {
+		return 0, false
+	}
+	return compare(a, b), true
+}
+
+// mixesIntAndFloat reports whether one of left and right is a Float and the
+// other an integer, which Promote can only compare after rounding the
+// integer to the nearest float.
+func mixesIntAndFloat(left, right Value) bool {
+	_, leftFloat := left.(Float)
+	_, rightFloat := right.(Float)
+	_, leftInt := left.(Int)
+	_, rightInt := right.(Int)
+	_, leftBig := left.(BigInt)
+	_, rightBig := right.(BigInt)
+	return leftFloat && (rightInt || rightBig) || rightFloat && (leftInt || leftBig)
+}
+
+// compareExactly compares an integer and a float by their exact values, so
+// 9007199254740993 is greater than 9007199254740992.0 although it rounds to
+// it as a float.
+func compareExactly(left, right Value) (int, bool) {
+	exact := func(v Value) (*big.Float, bool) {
+		switch v := v.(type) {
+		case Int:
+			return new(big.Float).SetInt64(int64(v)), true
+		case BigInt:
+			return new(big.Float).SetInt(v.Int), true
+		}
+		f := float64(v.(Float))
+		if math.IsNaN(f) {
+			return nil, false
+		}
+		return new(big.Float).SetFloat64(f), true
+	}
+	l, leftOk := exact(left)
+	r, rightOk := exact(right)
+	if !leftOk || !rightOk {
+		return 0, false
+	}
+	return l.Cmp(r), true
+}
+
+func compare[T Int | Float](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
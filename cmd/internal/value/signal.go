@@ -0,0 +1,48 @@
+package value
+
+import "github.com/iamBharatManral/atom.git/cmd/internal/diagnostic"
+
+// Signal interrupts the evaluation of the enclosing statements until it
+// reaches the construct that handles it: a function call for a Return, a
+// loop for a Break or a Continue, and the program itself for an Error.
+type Signal interface {
+	signal()
+}
+
+// Return ends the function call it is raised in, which evaluates to Value.
+type Return struct {
+	Value Value
+}
+
+// Break ends the loop it is raised in. The loop evaluates to Value unless
+// it is nil.
+type Break struct {
+	Value Value
+}
+
+// Continue ends the current iteration of the loop it is raised in.
+type Continue struct{}
+
+// Error is a runtime error. Its diagnostic gives the kind of error by its
+// code, the message and the span of the node that raised it, and Frames the
+// calls to Atom functions that were active then, innermost first.
+type Error struct {
+	diagnostic.Diagnostic
+	Frames []diagnostic.Frame
+}
+
+// Render formats the error as a diagnostic followed, when it was raised
+// inside a function, by a traceback of the calls that led to it. source
+// returns the text that a file name in a position refers to.
+func (e *Error) Render(source func(file string) []rune, color bool) string {
+	out := e.Diagnostic.Render(source(e.Pos.File), color)
+	if len(e.Frames) > 0 {
+		out += diagnostic.Traceback(e.Frames, e.Pos, source, color)
+	}
+	return out
+}
+
+func (Return) signal()   {}
+func (Break) signal()    {}
+func (Continue) signal() {}
+func (*Error) signal()   {}
@@ -0,0 +1,128 @@
+// Package value defines the values Atom programs compute with and the
+// signals that interrupt their evaluation.
+package value
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a value as Atom programs see it.
+type Kind int
+
+const (
+	NilKind Kind = iota
+	BoolKind
+	IntKind
+	FloatKind
+	DecimalKind
+	StrKind
+	RangeKind
+	ListKind
+	TupleKind
+	MapKind
+	FunctionKind
+	BuiltinKind
+)
+
+var kinds = []string{"nil", "bool", "int", "float", "decimal", "string", "range", "list", "tuple", "map", "function", "builtin"}
+
+func (k Kind) String() string {
+	return kinds[k]
+}
+
+// Value is a value computed by an Atom program.
+type Value interface {
+	Kind() Kind
+	// String formats the value the way interpolation shows it.
+	String() string
+	// Equal reports whether the value equals other. Numbers of different
+	// kinds are equal when they are the same number, and collections are
+	// compared element by element.
+	Equal(other Value) bool
+}
+
+// Hashable is a value that can be used as a map key.
+type Hashable interface {
+	Value
+	// Hash returns the key identifying the value in a map. Equal values
+	// have equal keys.
+	Hash() Key
+}
+
+// Key identifies a hashable value in a map.
+type Key struct {
+	kind Kind
+	bits uint64
+	text string
+}
+
+// Ordered is a value that can be compared with '<' and '>'.
+type Ordered interface {
+	Value
+	// Compare returns -1, 0 or +1 as the value is less than, equal to or
+	// greater than other. It reports false when the two cannot be ordered
+	// because they are not both numbers or both strings, or because one of
+	// them is NaN.
+	Compare(other Value) (int, bool)
+}
+
+// Nil is the value of statements and expressions that produce nothing,
+// such as a let or an if without an else whose test is false.
+type Nil struct{}
+
+func (Nil) Kind() Kind     { return NilKind }
+func (Nil) String() string { return "nil" }
+
+func (Nil) Equal(other Value) bool {
+	_, ok := other.(Nil)
+	return ok
+}
+
+type Bool bool
+
+func (b Bool) Kind() Kind     { return BoolKind }
+func (b Bool) String() string { return strconv.FormatBool(bool(b)) }
+
+func (b Bool) Equal(other Value) bool {
+	o, ok := other.(Bool)
+	return ok && b == o
+}
+
+func (b Bool) Hash() Key {
+	if b {
+		return Key{kind: BoolKind, bits: 1}
+	}
+	return Key{kind: BoolKind}
+}
+
+type Str string
+
+func (s Str) Kind() Kind     { return StrKind }
+func (s Str) String() string { return string(s) }
+
+func (s Str) Equal(other Value) bool {
+	o, ok := other.(Str)
+	return ok && s == o
+}
+
+func (s Str) Hash() Key {
+	return Key{kind: StrKind, text: string(s)}
+}
+
+func (s Str) Compare(other Value) (int, bool) {
+	o, ok := other.(Str)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(string(s), string(o)), true
+}
+
+// Inspect formats v nested inside a collection, quoting strings so they
+// can be told apart from other values.
+func Inspect(v Value) string {
+	if s, ok := v.(Str); ok {
+		return strconv.Quote(string(s))
+	}
+	return v.String()
+}
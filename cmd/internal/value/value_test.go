@@ -0,0 +1,168 @@
+package value
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/iamBharatManral/atom.git/cmd/internal/decimal"
+)
+
+func dec(s string) Decimal {
+	d, err := decimal.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return Decimal{Decimal: d}
+}
+
+func bigInt(s string) BigInt {
+	n, _ := new(big.Int).SetString(s, 10)
+	return BigInt{n}
+}
+
+func TestEqualValuesHashEqual(t *testing.T) {
+	big1, _ := new(big.Int).SetString("100000000000000000000", 10)
+	big2, _ := new(big.Int).SetString("100000000000000000000", 10)
+	tests := []struct {
+		name        string
+		left, right Hashable
+		equal       bool
+	}{
+		{name: "int and float", left: Int(1), right: Float(1.0), equal: true},
+		{name: "int and decimal", left: Int(2), right: dec("2.00"), equal: true},
+		{name: "float and decimal do not mix", left: Float(2.5), right: dec("2.50"), equal: false},
+		{name: "decimals with trailing zeros", left: dec("2.5"), right: dec("2.500"), equal: true},
+		{name: "big ints", left: BigInt{big1}, right: BigInt{big2}, equal: true},
+		{name: "big int and float", left: BigInt{big1}, right: Float(1e20), equal: true},
+		{name: "int above 2^53 and float", left: Int(9007199254740993), right: Float(9007199254740992.0), equal: false},
+		{name: "int at 2^53 and float", left: Int(9007199254740992), right: Float(9007199254740992.0), equal: true},
+		{name: "big int next to float", left: bigInt("100000000000000000001"), right: Float(1e20), equal: false},
+		{name: "different numbers", left: Int(1), right: Float(1.5), equal: false},
+		{name: "int and string", left: Int(1), right: Str("1"), equal: false},
+		{name: "int and bool", left: Int(1), right: Bool(true), equal: false},
+		{name: "strings", left: Str("a"), right: Str("a"), equal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.left.Equal(tt.right); got != tt.equal {
+				t.Errorf("Equal: got %v, want %v", got, tt.equal)
+			}
+			if got := tt.left.Hash() == tt.right.Hash(); got != tt.equal {
+				t.Errorf("Hash: got equal %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right Ordered
+		want        int
+		ok          bool
+	}{
+		{name: "ints", left: Int(1), right: Int(2), want: -1, ok: true},
+		{name: "int and float", left: Int(2), right: Float(1.5), want: 1, ok: true},
+		{name: "decimal and int", left: dec("2.0"), right: Int(2), want: 0, ok: true},
+		{name: "strings", left: Str("b"), right: Str("a"), want: 1, ok: true},
+		{name: "int above 2^53 and float", left: Int(9007199254740993), right: Float(9007199254740992.0), want: 1, ok: true},
+		{name: "float and int above 2^53", left: Float(9007199254740992.0), right: Int(9007199254740993), want: -1, ok: true},
+		{name: "int at 2^53 and float", left: Int(9007199254740992), right: Float(9007199254740992.0), want: 0, ok: true},
+		{name: "big int and float", left: bigInt("99999999999999999999"), right: Float(1e20), want: -1, ok: true},
+		{name: "big int equal to float", left: bigInt("100000000000000000000"), right: Float(1e20), want: 0, ok: true},
+		{name: "int and infinity", left: Int(math.MaxInt), right: Float(math.Inf(1)), want: -1, ok: true},
+		{name: "NaN and big int", left: Float(math.NaN()), right: bigInt("100000000000000000000"), ok: false},
+		{name: "NaN", left: Float(math.NaN()), right: Int(1), ok: false},
+		{name: "number and string", left: Int(1), right: Str("1"), ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.left.Compare(tt.right)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("got %d, %v, want %d, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	m := NewMap()
+	m.Set(Str("z"), Int(1))
+	m.Set(Int(1), Str("a"))
+	m.Set(Str("y"), Int(2))
+	m.Set(Float(1.0), Str("b"))
+	if got, want := m.String(), `{"z": 1, 1: "b", "y": 2}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	m.Set(Float(9007199254740992.0), Str("f"))
+	if _, ok := m.Get(Int(9007199254740993)); ok {
+		t.Errorf("Get found 9007199254740993 under 9007199254740992.0")
+	}
+	if got, ok := m.Get(Int(9007199254740992)); !ok || got != Str("f") {
+		t.Errorf("Get(9007199254740992): got %v, %v", got, ok)
+	}
+	m.Delete(Float(9007199254740992.0))
+	if !m.Delete(Str("z")) || m.Delete(Str("z")) {
+		t.Errorf("Delete did not report the presence of the key")
+	}
+	if got, want := m.String(), `{1: "b", "y": 2}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		rng      Range
+		len      int
+		fits     bool
+		contains []int
+		excludes []int
+	}{
+		{name: "inclusive", rng: Range{From: 1, To: 5, Step: 2, Inclusive: true}, len: 3, fits: true, contains: []int{1, 3, 5}, excludes: []int{0, 2, 7}},
+		{name: "exclusive descending", rng: Range{From: 5, To: 1, Step: -1}, len: 4, fits: true, contains: []int{5, 2}, excludes: []int{1, 6}},
+		{name: "empty", rng: Range{From: 3, To: 3, Step: 1}, len: 0, fits: true, excludes: []int{3}},
+		{name: "wrong direction", rng: Range{From: 1, To: 5, Step: -1, Inclusive: true}, len: 0, fits: true, excludes: []int{1, 5}},
+		{name: "widest", rng: Range{From: math.MinInt, To: math.MaxInt, Step: 1, Inclusive: true}, fits: false, contains: []int{math.MinInt, 0, math.MaxInt}},
+		{name: "wide but countable", rng: Range{From: -1, To: math.MaxInt - 1, Step: 1}, len: math.MaxInt, fits: true, contains: []int{-1, math.MaxInt - 2}, excludes: []int{math.MaxInt - 1}},
+		{name: "wide with a big step", rng: Range{From: math.MaxInt, To: math.MinInt, Step: math.MinInt, Inclusive: true}, len: 2, fits: true, contains: []int{math.MaxInt, -1}, excludes: []int{math.MinInt, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n, fits := tt.rng.Len(); n != tt.len || fits != tt.fits {
+				t.Errorf("Len: got %d, %v, want %d, %v", n, fits, tt.len, tt.fits)
+			}
+			for _, n := range tt.contains {
+				if !tt.rng.Contains(n) {
+					t.Errorf("Contains(%d): got false", n)
+				}
+			}
+			for _, n := range tt.excludes {
+				if tt.rng.Contains(n) {
+					t.Errorf("Contains(%d): got true", n)
+				}
+			}
+		})
+	}
+}
+
+func TestRangeEach(t *testing.T) {
+	var got []int
+	Range{From: math.MaxInt - 4, To: math.MaxInt, Step: 2, Inclusive: true}.Each(func(n int) bool {
+		got = append(got, n)
+		return true
+	})
+	if want := []int{math.MaxInt - 4, math.MaxInt - 2, math.MaxInt}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got = nil
+	Range{From: math.MinInt, To: math.MaxInt, Step: 1, Inclusive: true}.Each(func(n int) bool {
+		got = append(got, n)
+		return len(got) < 2
+	})
+	if want := []int{math.MinInt, math.MinInt + 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}